@@ -0,0 +1,325 @@
+// Package converter converts SignalFx dashboard groups, dashboards, charts
+// and detectors to terraform resources of the signalfx provider.
+//
+//	conv, err := converter.New(token, converter.Realm("us1"))
+//	if err != nil {
+//		return err
+//	}
+//	result, err := conv.ConvertDashboard(ctx, "Dw1xyzAYAAA")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("%s", result.Files.Bytes())
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/signalfx/signalfx-go"
+	"github.com/signalfx/signalfx-go/dashboard"
+	"github.com/signalfx/signalfx-go/dashboard_group"
+
+	"github.com/doctornkz/signalfx2terraform/src/detectors"
+	"github.com/doctornkz/signalfx2terraform/src/fetch"
+	"github.com/doctornkz/signalfx2terraform/src/output"
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+// DefaultRealm - realm used when neither realm nor API URL are specified
+const DefaultRealm = "eu0"
+
+// RealmURL - API entrypoint of the realm
+func RealmURL(realm string) string {
+	if realm == "" {
+		realm = DefaultRealm
+	}
+	return fmt.Sprintf("https://api.%s.signalfx.com", realm)
+}
+
+// Converter - converts SignalFx objects fetched from API or decoded from JSON.
+// Labels of generated resources are unique across all results of the converter.
+// Converter is safe for concurrent use with different results, one Result must not be written concurrently.
+type Converter struct {
+	token       string
+	realm       string
+	apiURL      string // custom URL, it takes precedence over realm
+	layout      string
+	naming      *utils.NamingPolicy
+	labels      *utils.Labeler
+	httpClient  *http.Client
+	workers     int
+	secrets     bool
+	credentials bool
+
+	client *signalfx.Client // search API
+	api    *fetch.API       // objects, decoded from raw JSON
+}
+
+// Option - converter setting, see New
+type Option func(*Converter) error
+
+// Realm - use API of the realm (eu0, us0, us1, us2, ...), it's ignored if APIURL is set
+func Realm(realm string) Option {
+	return func(c *Converter) error {
+		c.realm = realm
+		return nil
+	}
+}
+
+// APIURL - use custom API URL instead of realm, empty URL is ignored
+func APIURL(url string) Option {
+	return func(c *Converter) error {
+		if url != "" {
+			c.apiURL = strings.TrimRight(url, "/")
+		}
+		return nil
+	}
+}
+
+// Layout - distribution of resources by files, see output.New
+func Layout(layout string) Option {
+	return func(c *Converter) error {
+		if _, err := output.New(layout); err != nil {
+			return err
+		}
+		c.layout = layout
+		return nil
+	}
+}
+
+// Naming - names of generated resources
+func Naming(naming *utils.NamingPolicy) Option {
+	return func(c *Converter) error {
+		c.naming = naming
+		return nil
+	}
+}
+
+// Labels - terraform labels of generated resources, e.g. loaded from label map
+func Labels(labels *utils.Labeler) Option {
+	return func(c *Converter) error {
+		c.labels = labels
+		return nil
+	}
+}
+
+// HTTPClient - client for all API requests, see fetch.NewHTTPClient
+func HTTPClient(httpClient *http.Client) Option {
+	return func(c *Converter) error {
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// Workers - amount of concurrent chart requests, values below 1 are ignored
+func Workers(workers int) Option {
+	return func(c *Converter) error {
+		if workers > 0 {
+			c.workers = workers
+		}
+		return nil
+	}
+}
+
+// Variables - replace webhook secrets with sensitive terraform variables
+// and optionally credential ids with variables, see Result.Variables
+func Variables(secrets bool, credentials bool) Option {
+	return func(c *Converter) error {
+		c.secrets = secrets
+		c.credentials = credentials
+		return nil
+	}
+}
+
+// New - create converter with API token and options,
+// token isn't required for conversion of JSON objects
+func New(token string, options ...Option) (*Converter, error) {
+	c := &Converter{
+		token:   token,
+		layout:  output.LayoutSingle,
+		labels:  utils.NewLabeler(),
+		workers: fetch.DefaultWorkers,
+	}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.apiURL == "" {
+		c.apiURL = RealmURL(c.realm)
+	}
+	if c.httpClient == nil {
+		c.httpClient = fetch.NewHTTPClient(fetch.DefaultConfig())
+	}
+
+	client, err := fetch.NewClient(c.token, c.apiURL, c.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("Something wrong with API client: %v", err)
+	}
+	c.client = client
+	c.api = &fetch.API{Client: c.httpClient, URL: c.apiURL, Token: c.token}
+	return c, nil
+}
+
+// APIURL - API entrypoint used by the converter
+func (c *Converter) APIURL() string {
+	return c.apiURL
+}
+
+// Result - generated terraform files, conversion diagnostics and variables,
+// variables are added to files with Files.Variables
+type Result struct {
+	Files       *output.Files
+	Diagnostics utils.Diagnostics
+	Variables   *utils.Variables
+}
+
+// NewResult - empty result, objects are added with Add* and Write* methods
+func (c *Converter) NewResult() *Result {
+	// Layout is checked by the option
+	files, _ := output.New(c.layout)
+	return &Result{Files: files, Variables: utils.NewVariables(c.secrets, c.credentials)}
+}
+
+// options - converter settings for objects written to the result
+func (c *Converter) options(r *Result) *utils.Options {
+	return &utils.Options{Naming: c.naming, Labels: c.labels, Diagnostics: &r.Diagnostics, Variables: r.Variables}
+}
+
+// ConvertDashboard - convert dashboard with its charts
+func (c *Converter) ConvertDashboard(ctx context.Context, id string) (*Result, error) {
+	r := c.NewResult()
+	if err := c.AddDashboard(ctx, r, id); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ConvertDashboardGroup - convert dashboard group with all its dashboards and charts
+func (c *Converter) ConvertDashboardGroup(ctx context.Context, id string) (*Result, error) {
+	r := c.NewResult()
+	if err := c.AddDashboardGroup(ctx, r, id); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ConvertDetector - convert detector, detectors of old version API are supported
+func (c *Converter) ConvertDetector(ctx context.Context, id string) (*Result, error) {
+	r := c.NewResult()
+	if err := c.AddDetector(ctx, r, id); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddDashboard - fetch dashboard with its charts and add them to the result
+func (c *Converter) AddDashboard(ctx context.Context, r *Result, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dashboard, err := c.api.Dashboard(ctx, id)
+	if err != nil {
+		return fmt.Errorf("Can't fetch dashboard %s: %w", id, err)
+	}
+	return c.FetchDashboard(ctx, r, dashboard)
+}
+
+// AddDashboardGroup - fetch dashboard group with all its dashboards and charts and add them to the result
+func (c *Converter) AddDashboardGroup(ctx context.Context, r *Result, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	group, err := c.api.DashboardGroup(ctx, id)
+	if err != nil {
+		return fmt.Errorf("Can't fetch dashboard group %s: %w", id, err)
+	}
+	return c.FetchDashboardGroup(ctx, r, group)
+}
+
+// AddDetector - fetch detector and add it to the result,
+// detectors which are missing in current API are fetched from old version API
+func (c *Converter) AddDetector(ctx context.Context, r *Result, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	detector, err := detectors.GetDetector(ctx, c.httpClient, c.apiURL, id, c.token)
+	if err == nil {
+		return c.WriteDetector(r, detector)
+	}
+	if !errors.Is(err, detectors.ErrNotFound) {
+		return fmt.Errorf("Can't fetch detector %s: %w", id, err)
+	}
+
+	detectorV1, err := detectors.GetDetectorV1(ctx, c.httpClient, c.apiURL, id, c.token)
+	if errors.Is(err, detectors.ErrNotFound) {
+		return fmt.Errorf("Detector %s %w in v2 and v1 API", id, err)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't fetch detector %s from v1 API: %w", id, err)
+	}
+	return c.WriteDetectorV1(r, detectorV1)
+}
+
+// FetchDetector - fetch detector of current API and add it to the result
+func (c *Converter) FetchDetector(ctx context.Context, r *Result, id string) error {
+	detector, err := detectors.GetDetector(ctx, c.httpClient, c.apiURL, id, c.token)
+	if err != nil {
+		return fmt.Errorf("Can't fetch detector %s: %w", id, err)
+	}
+	return c.WriteDetector(r, detector)
+}
+
+// FetchDashboardGroup - fetch all dashboards of the group with their charts and add them with the group to the result,
+// nothing is added if any dashboard can't be fetched
+func (c *Converter) FetchDashboardGroup(ctx context.Context, r *Result, group *dashboard_group.DashboardGroup) error {
+	dashboards := make([]*dashboard.Dashboard, len(group.Dashboards))
+	charts := make([]map[string]*utils.Chart, len(group.Dashboards))
+	for i, id := range group.Dashboards {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dashboard, err := c.api.Dashboard(ctx, id)
+		if err != nil {
+			return fmt.Errorf("Can't fetch dashboard %s of group %s: %w", id, group.Id, err)
+		}
+
+		dashboards[i] = dashboard
+		charts[i], err = c.fetchCharts(ctx, dashboard)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.WriteDashboardGroup(r, group); err != nil {
+		return err
+	}
+	for i, dashboard := range dashboards {
+		if err := c.WriteDashboard(r, dashboard, charts[i], group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchDashboard - fetch charts of the dashboard and add dashboard with charts to the result
+func (c *Converter) FetchDashboard(ctx context.Context, r *Result, dashboard *dashboard.Dashboard) error {
+	charts, err := c.fetchCharts(ctx, dashboard)
+	if err != nil {
+		return err
+	}
+	return c.WriteDashboard(r, dashboard, charts, nil)
+}
+
+// fetchCharts - fetch all charts of the dashboard
+func (c *Converter) fetchCharts(ctx context.Context, dashboard *dashboard.Dashboard) (map[string]*utils.Chart, error) {
+	charts, err := fetch.DashboardCharts(ctx, c.api, dashboard, c.workers)
+	if err != nil {
+		return nil, fmt.Errorf("Can't get charts of dashboard %s: %w", dashboard.Id, err)
+	}
+	return charts, nil
+}
@@ -0,0 +1,29 @@
+package converter
+
+import "testing"
+
+func TestNewAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		want    string
+	}{
+		{"default realm", nil, "https://api.eu0.signalfx.com"},
+		{"realm", []Option{Realm("us1")}, "https://api.us1.signalfx.com"},
+		{"API URL after realm", []Option{Realm("us1"), APIURL("http://localhost:8080/")}, "http://localhost:8080"},
+		{"realm after API URL", []Option{APIURL("http://localhost:8080"), Realm("us1")}, "http://localhost:8080"},
+		{"empty API URL", []Option{APIURL(""), Realm("us1")}, "https://api.us1.signalfx.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New("", tt.options...)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			if got := conv.APIURL(); got != tt.want {
+				t.Errorf("APIURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
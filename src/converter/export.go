@@ -0,0 +1,197 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+// Resource types of the export
+const (
+	ResourceDashboardGroup = "dashboard-group"
+	ResourceDashboard      = "dashboard"
+	ResourceDetector       = "detector"
+)
+
+// pageLimit - amount of objects requested from search API at once
+const pageLimit = 100
+
+// Filter - filters applied to objects found by search API
+type Filter struct {
+	Resources []string       // resource types to export, all types if empty
+	Name      *regexp.Regexp // object name matches regex
+	Tag       string         // object has tag, dashboard groups don't have tags
+	Team      string         // object belongs to team, dashboards don't have teams
+	Since     int64          // object is modified since Unix time in ms
+}
+
+// Match - check object against filters,
+// nil tags or teams mean the object type doesn't support this filter
+func (e *Filter) Match(name string, tags []string, teams []string, updated int64) bool {
+	if e.Name != nil && !e.Name.MatchString(name) {
+		return false
+	}
+	if e.Tag != "" && !contains(tags, e.Tag) {
+		return false
+	}
+	if e.Team != "" && !contains(teams, e.Team) {
+		return false
+	}
+	return updated >= e.Since
+}
+
+// exports - check if resource type is exported
+func (e *Filter) exports(resource string) bool {
+	return len(e.Resources) == 0 || contains(e.Resources, resource)
+}
+
+// contains - check if list contains value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Export - add all objects matching filter to the result,
+// objects which can't be fetched or converted are skipped with diagnostic
+func (c *Converter) Export(ctx context.Context, r *Result, filter *Filter) error {
+	for _, resource := range filter.Resources {
+		if resource != ResourceDashboardGroup && resource != ResourceDashboard && resource != ResourceDetector {
+			return fmt.Errorf("Unknown resource type %s", resource)
+		}
+	}
+
+	// Dashboards exported as part of a group are skipped later
+	exported := map[string]bool{}
+
+	if filter.exports(ResourceDashboardGroup) {
+		if err := c.exportDashboardGroups(ctx, r, filter, exported); err != nil {
+			return err
+		}
+	}
+	if filter.exports(ResourceDashboard) {
+		if err := c.exportDashboards(ctx, r, filter, exported); err != nil {
+			return err
+		}
+	}
+	if filter.exports(ResourceDetector) {
+		if err := c.exportDetectors(ctx, r, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportDashboardGroups - process all dashboard groups matching filters
+func (c *Converter) exportDashboardGroups(ctx context.Context, r *Result, filter *Filter, exported map[string]bool) error {
+	// Dashboard groups don't have tags
+	if filter.Tag != "" {
+		return nil
+	}
+	for offset := 0; ; offset += pageLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := c.client.SearchDashboardGroups(pageLimit, "", offset)
+		if err != nil {
+			return fmt.Errorf("Can't search dashboard groups: %v", err)
+		}
+
+		for _, group := range result.Results {
+			if !filter.Match(group.Name, nil, group.Teams, group.LastUpdated) {
+				continue
+			}
+			log.Printf("Dashboard group processing: %s", group.Id)
+			if err := c.FetchDashboardGroup(ctx, r, group); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.Diagnostics.Add(utils.KindDashboardGroup, group.Id, "", utils.SeverityDropped, "skipped: %v", err)
+				continue
+			}
+			for _, d := range group.Dashboards {
+				exported[d] = true
+			}
+		}
+
+		if len(result.Results) < pageLimit {
+			return nil
+		}
+	}
+}
+
+// exportDashboards - process all dashboards matching filters
+func (c *Converter) exportDashboards(ctx context.Context, r *Result, filter *Filter, exported map[string]bool) error {
+	// Dashboards don't have teams
+	if filter.Team != "" {
+		return nil
+	}
+	for offset := 0; ; offset += pageLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := c.client.SearchDashboard(pageLimit, "", offset, filter.Tag)
+		if err != nil {
+			return fmt.Errorf("Can't search dashboards: %v", err)
+		}
+
+		for i := range result.Results {
+			dashboard := &result.Results[i]
+			if exported[dashboard.Id] {
+				continue
+			}
+			if !filter.Match(dashboard.Name, dashboard.Tags, nil, dashboard.LastUpdated) {
+				continue
+			}
+			log.Printf("Dashboard processing: %s", dashboard.Id)
+			if err := c.FetchDashboard(ctx, r, dashboard); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.Diagnostics.Add(utils.KindDashboard, dashboard.Id, "", utils.SeverityDropped, "skipped: %v", err)
+			}
+		}
+
+		if len(result.Results) < pageLimit {
+			return nil
+		}
+	}
+}
+
+// exportDetectors - process all detectors matching filters
+func (c *Converter) exportDetectors(ctx context.Context, r *Result, filter *Filter) error {
+	for offset := 0; ; offset += pageLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := c.client.SearchDetectors(pageLimit, "", offset, filter.Tag)
+		if err != nil {
+			return fmt.Errorf("Can't search detectors: %v", err)
+		}
+
+		for i := range result.Results {
+			detector := &result.Results[i]
+			if !filter.Match(detector.Name, detector.Tags, detector.Teams, detector.LastUpdated) {
+				continue
+			}
+			log.Printf("Detector processing: %s", detector.Id)
+			// Search results are decoded with signalfx-go model, full detector is fetched again
+			if err := c.FetchDetector(ctx, r, detector.Id); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.Diagnostics.Add(utils.KindDetector, detector.Id, "", utils.SeverityDropped, "skipped: %v", err)
+			}
+		}
+
+		if len(result.Results) < pageLimit {
+			return nil
+		}
+	}
+}
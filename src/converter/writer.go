@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/signalfx/signalfx-go/dashboard"
+	"github.com/signalfx/signalfx-go/dashboard_group"
+
+	"github.com/doctornkz/signalfx2terraform/src/detectors"
+	"github.com/doctornkz/signalfx2terraform/src/eventfeed"
+	"github.com/doctornkz/signalfx2terraform/src/heatmap"
+	"github.com/doctornkz/signalfx2terraform/src/list"
+	"github.com/doctornkz/signalfx2terraform/src/singlevalue"
+	"github.com/doctornkz/signalfx2terraform/src/table"
+	"github.com/doctornkz/signalfx2terraform/src/text"
+	"github.com/doctornkz/signalfx2terraform/src/timeseries"
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+// WriteDashboardGroup - add dashboard group without its dashboards to the result
+func (c *Converter) WriteDashboardGroup(r *Result, group *dashboard_group.DashboardGroup) error {
+	opts := c.options(r)
+	if _, err := utils.CreateDashboardGroup(r.Files.DashboardGroup(group.Id, group.Name), group, opts); err != nil {
+		return err
+	}
+	r.Files.Import("signalfx_dashboard_group", opts.Label(group.Id, group.Name), group.Id)
+	return nil
+}
+
+// WriteDashboard - add dashboard and its charts to the result,
+// charts contains all charts of the dashboard by id.
+// If group is not nil, dashboard refers to the group resource written with WriteDashboardGroup.
+func (c *Converter) WriteDashboard(r *Result, dashboard *dashboard.Dashboard, charts map[string]*utils.Chart, group *dashboard_group.DashboardGroup) error {
+	opts := c.options(r)
+
+	groupLabel := ""
+	if group != nil {
+		groupLabel = opts.Label(group.Id, group.Name)
+	}
+
+	if _, err := utils.CreateDashboard(r.Files.Dashboard(dashboard.Id, dashboard.Name), dashboard, charts, groupLabel, opts); err != nil {
+		return err
+	}
+	r.Files.Import("signalfx_dashboard", opts.Label(dashboard.Id, dashboard.Name), dashboard.Id)
+
+	// All charts are checked by CreateDashboard,
+	// chart placed several times on the dashboard is written once
+	written := map[string]bool{}
+	for _, v := range dashboard.Charts {
+		if written[v.ChartId] {
+			continue
+		}
+		written[v.ChartId] = true
+		if err := c.writeChart(r, dashboard.Id, dashboard.Name, charts[v.ChartId]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteChart - add chart which isn't used by any written dashboard to the result
+func (c *Converter) WriteChart(r *Result, chart *utils.Chart) error {
+	return c.writeChart(r, chart.Id, chart.Name, chart)
+}
+
+// writeChart - add chart of the dashboard to the result, unsupported chart types are skipped
+func (c *Converter) writeChart(r *Result, dashboardID string, dashboardName string, chart *utils.Chart) error {
+	opts := c.options(r)
+
+	if _, ok := utils.Type[chart.Options.Type]; !ok {
+		opts.Diagnoser(utils.KindChart, chart.Id).Unsupported("options.type", "chart type %s is not supported, chart is skipped", chart.Options.Type)
+		return nil
+	}
+
+	f := r.Files.Chart(dashboardID, dashboardName, chart.Id, chart.Name)
+
+	var err error
+	switch types := chart.Options.Type; types {
+	case "SingleValue":
+		_, err = singlevalue.Chart(f, chart, opts)
+	case "Heatmap":
+		_, err = heatmap.Chart(f, chart, opts)
+	case "TimeSeriesChart":
+		_, err = timeseries.Chart(f, chart, opts)
+	case "List":
+		_, err = list.Chart(f, chart, opts)
+	case "Text":
+		_, err = text.Chart(f, chart, opts)
+	case "Event":
+		_, err = eventfeed.Chart(f, chart, opts)
+	case "TableChart":
+		_, err = table.Chart(f, chart, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't convert chart %s: %v", chart.Id, err)
+	}
+
+	r.Files.Import(utils.Type[chart.Options.Type], opts.Label(chart.Id, chart.Name), chart.Id)
+	return nil
+}
+
+// WriteDetector - add detector to the result
+func (c *Converter) WriteDetector(r *Result, detector *utils.Detector) error {
+	opts := c.options(r)
+	if _, err := detectors.CreateDetector(r.Files.Detector(detector.Id, detector.Name), detector, opts); err != nil {
+		return err
+	}
+	r.Files.Import("signalfx_detector", opts.Label(detector.Id, detector.Name), detector.Id)
+	return nil
+}
+
+// WriteDetectorV1 - add detector created with old version API to the result
+func (c *Converter) WriteDetectorV1(r *Result, detector *utils.DetectorV1) error {
+	opts := c.options(r)
+	if _, err := detectors.CreateDetectorV1(r.Files.Detector(detector.Sf_id, detector.Sf_detector), detector, opts); err != nil {
+		return err
+	}
+	r.Files.Import("signalfx_detector", opts.Label(detector.Sf_id, detector.Sf_detector), detector.Sf_id)
+	return nil
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/signalfx/signalfx-go/dashboard"
+
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+// decode - object decoded from JSON of API response
+func decode(t *testing.T, js string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(js), v); err != nil {
+		t.Fatalf("Can't decode %s: %v", js, err)
+	}
+}
+
+func TestWriteDashboardRepeatedChart(t *testing.T) {
+	conv, err := New("")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	var d dashboard.Dashboard
+	decode(t, `{"id": "D1", "name": "hosts", "filters": {"time": {"start": "-1h", "end": "Now"}}, "charts": [
+		{"chartId": "C1", "row": 0, "column": 0, "width": 6, "height": 1},
+		{"chartId": "C1", "row": 0, "column": 6, "width": 6, "height": 1}
+	]}`, &d)
+	var chart utils.Chart
+	decode(t, `{"id": "C1", "name": "cpu", "programText": "A = data('cpu').publish()", "options": {"type": "TimeSeriesChart"}}`, &chart)
+
+	r := conv.NewResult()
+	if err := conv.WriteDashboard(r, &d, map[string]*utils.Chart{"C1": &chart}, nil); err != nil {
+		t.Fatalf("WriteDashboard() error: %v", err)
+	}
+	r.Files.ImportBlocks()
+	content := r.Files.Bytes()
+
+	if n := bytes.Count(content, []byte(`resource "signalfx_time_chart"`)); n != 1 {
+		t.Errorf("%d chart resources, want 1", n)
+	}
+	if n := bytes.Count(content, []byte(`to = signalfx_time_chart.`)); n != 1 {
+		t.Errorf("%d chart import blocks, want 1", n)
+	}
+	if n := bytes.Count(content, []byte("chart_id")); n != 2 {
+		t.Errorf("%d chart positions, want 2", n)
+	}
+}
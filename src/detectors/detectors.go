@@ -1,140 +1,224 @@
 package detectors
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"net/http"
+	"regexp"
 
+	"github.com/doctornkz/signalfx2terraform/src/fetch"
 	"github.com/doctornkz/signalfx2terraform/src/utils"
-	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hclwrite"
 	"github.com/signalfx/signalfx-go/detector"
-
 	"github.com/zclconf/go-cty/cty"
 )
 
 // CreateDetector - function for generating detector from API
-func CreateDetector(f *hclwrite.File, detector *detector.Detector) *hclwrite.Body {
-
+func CreateDetector(f *hclwrite.File, detector *utils.Detector, opts *utils.Options) (*hclwrite.Body, error) {
 	// wrapper around label
-	label := utils.LabelProc(detector.Id)
+	label := opts.Label(detector.Id, detector.Name)
+	// diagnostics of the detector
+	d := opts.Diagnoser(utils.KindDetector, detector.Id)
 
 	rootBody := f.Body()
 	detectorBlock := rootBody.AppendNewBlock("resource", []string{"signalfx_detector", label})
 	detectorBody := detectorBlock.Body()
 
-	detectorBody.SetAttributeValue("name", cty.StringVal(fmt.Sprintf("test-%s", detector.Name))) // TODO: Hardcoded to prevent self-destroy
+	detectorBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindDetector, detector.Id, detector.Name)))
 	detectorBody.SetAttributeValue("description", cty.StringVal(detector.Description))
 
-	teams := utils.ListOfTeamsDetectorProc(detector)
+	teams := utils.ListOfTeamsDetectorProc(&detector.Detector)
 	if len(teams) > 0 {
 		detectorBody.SetAttributeValue("teams", cty.ListVal(teams))
 	}
+	if len(detector.Tags) > 0 {
+		detectorBody.SetAttributeValue("tags", utils.StringListProc(detector.Tags))
+	}
+	if detector.AuthorizedWriters != nil {
+		if len(detector.AuthorizedWriters.Teams) > 0 {
+			detectorBody.SetAttributeValue("authorized_writer_teams", utils.StringListProc(detector.AuthorizedWriters.Teams))
+		}
+		if len(detector.AuthorizedWriters.Users) > 0 {
+			detectorBody.SetAttributeValue("authorized_writer_users", utils.StringListProc(detector.AuthorizedWriters.Users))
+		}
+	}
+
+	detectorBody.SetAttributeValue("max_delay", cty.NumberIntVal(utils.MaxDelayDetectorProc(&detector.Detector)))
+	if detector.MinDelay != nil && *detector.MinDelay > 0 {
+		detectorBody.SetAttributeValue("min_delay", cty.NumberIntVal(*detector.MinDelay/1000)) // Convert to sec
+	}
+	if detector.Timezone != "" {
+		detectorBody.SetAttributeValue("timezone", cty.StringVal(detector.Timezone))
+	}
+	LabelResolutionsProc(detector.LabelResolutions, "labelResolutions", d)
+	utils.HeredocProc(detectorBody, "program_text", detector.ProgramText)
 
-	detectorBody.SetAttributeValue("max_delay", cty.NumberIntVal(utils.MaxDelayDetectorProc(detector)))
-	detectorBody.SetAttributeTraversal("program_text", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: utils.ProgramTextProc(detector.ProgramText),
-		},
-	})
+	if detector.VisualizationOptions != nil {
+		VisualizationProc(detectorBody, detector.VisualizationOptions, d)
+	}
 
 	// Rules processing
 	for _, rule := range detector.Rules {
 		ruleBlock := detectorBody.AppendNewBlock("rule", nil)
-		ruleBody := ruleBlock.Body()
-
-		// get json encoded structure
-		js, err := json.Marshal(rule)
-		if err != nil {
-			log.Fatal("Cannot marshal the structure", err)
-		}
-
-		// map structure into our structure
-		s := utils.DetectorRule{}
-		json.Unmarshal(js, &s)
+		RuleProc(ruleBlock.Body(), rule, d, opts.Variables, label)
+	}
+	return detectorBody, nil
+}
 
-		m, _ := GetJsonWithTags(s)
-		for k, v := range m {
-			ruleBody.SetAttributeValue(k, cty.StringVal(v))
-		}
+// LabelResolutionsProc - label resolutions aren't written, they are computed by provider,
+// not empty resolutions are reported as dropped
+func LabelResolutionsProc(resolutions map[string]int64, field string, d *utils.Diagnoser) {
+	if len(resolutions) > 0 {
+		d.Dropped(field, "label resolutions are computed by provider, %d resolutions are not written", len(resolutions))
+	}
+}
 
-		// get notifications, this way is simpler than using struct
-		ruleBody.SetAttributeValue("notifications", utils.NotificationProc(*rule))
+// VisualizationProc - write visualization options of the detector, options with default values are omitted
+func VisualizationProc(detectorBody *hclwrite.Body, viz *utils.DetectorVisualization, d *utils.Diagnoser) {
+	if viz.DisableSampling {
+		detectorBody.SetAttributeValue("disable_sampling", cty.True)
+	}
+	if viz.ShowDataMarkers {
+		detectorBody.SetAttributeValue("show_data_markers", cty.True)
+	}
+	if viz.ShowEventLines {
+		detectorBody.SetAttributeValue("show_event_lines", cty.True)
+	}
 
+	// Time range processing
+	if t := viz.Time; t != nil {
+		utils.TimeRangeProc(detectorBody, "visualizationOptions.time", t.Type, t.Range, t.Start, t.End, d)
 	}
-	return detectorBody
+
+	utils.ShortVizProc(detectorBody, viz.PublishLabelOptions, "visualizationOptions.publishLabelOptions", d)
 }
 
-func GetJsonWithTags(v interface{}) (map[string]string, error) {
-	// remove unwanted fields, like notifications which we treat later in a simpler way
-	js, err := json.MarshalIndent(v, "", " ")
-	if err != nil {
-		log.Fatalf(err.Error())
-		return nil, err
+// RuleProc - write rule of the detector with label owner, empty optional attributes are omitted
+func RuleProc(ruleBody *hclwrite.Body, rule *detector.Rule, d *utils.Diagnoser, v *utils.Variables, owner string) {
+	if rule.Description != "" {
+		ruleBody.SetAttributeValue("description", cty.StringVal(rule.Description))
 	}
-
-	// print the structure with tags as keys
-	m := make(map[string]string)
-	err = json.Unmarshal(js, &m)
-	if err != nil {
-		log.Fatalf(err.Error())
-		return nil, err
+	ruleBody.SetAttributeValue("severity", cty.StringVal(string(rule.Severity)))
+	ruleBody.SetAttributeValue("detect_label", cty.StringVal(rule.DetectLabel))
+	if rule.Disabled {
+		ruleBody.SetAttributeValue("disabled", cty.True)
+	}
+	if rule.ParameterizedSubject != "" {
+		ruleBody.SetAttributeValue("parameterized_subject", cty.StringVal(rule.ParameterizedSubject))
+	}
+	if rule.ParameterizedBody != "" {
+		ruleBody.SetAttributeValue("parameterized_body", cty.StringVal(rule.ParameterizedBody))
+	}
+	if rule.RunbookUrl != "" {
+		ruleBody.SetAttributeValue("runbook_url", cty.StringVal(rule.RunbookUrl))
+	}
+	if rule.Tip != "" {
+		ruleBody.SetAttributeValue("tip", cty.StringVal(rule.Tip))
 	}
 
-	return m, nil
+	// get notifications, this way is simpler than using struct
+	utils.TemplateListProc(ruleBody, "notifications", utils.NotificationProc(*rule, d, v, owner), ",")
 }
 
-// CreateDetectorV1 - function for generating detector from old version API.
-func CreateDetectorV1(f *hclwrite.File, api string, detectorID string, token string) *hclwrite.Body {
-	client := &http.Client{}
-	detectorURL := fmt.Sprintf("%v/v1/detector/%v", api, detectorID)
-	req, err := http.NewRequest("GET", detectorURL, nil)
-	req.Header.Add("X-SF-TOKEN", token)
-	detectorResponse, err := client.Do(req)
+var (
+	// ErrNotFound - detector doesn't exist in the API version
+	ErrNotFound = fetch.ErrNotFound
+	// ErrUnauthorized - token is rejected by API
+	ErrUnauthorized = fetch.ErrUnauthorized
+)
 
-	if err != nil {
-		log.Fatalf("Can't fetch data from API %v, %v", detectorURL, err)
+// GetDetector - function for fetching detector from API,
+// detector is decoded from raw JSON because signalfx-go model misses some fields.
+// ErrNotFound is returned for detectors which exist only in old version API.
+func GetDetector(ctx context.Context, client *http.Client, api string, detectorID string, token string) (*utils.Detector, error) {
+	var detector utils.Detector
+	if err := fetch.GetJSON(ctx, client, fmt.Sprintf("%v/v2/detector/%v", api, detectorID), token, &detector); err != nil {
+		return nil, err
 	}
-	defer detectorResponse.Body.Close()
-	body, err := ioutil.ReadAll(detectorResponse.Body)
-	if err != nil {
-		log.Fatalf("Can't read body JSON, %v", err)
+	if detector.Id == "" {
+		return nil, fmt.Errorf("Response of v2 API is not a detector")
 	}
+	return &detector, nil
+}
 
+// GetDetectorV1 - function for fetching detector from old version API.
+func GetDetectorV1(ctx context.Context, client *http.Client, api string, detectorID string, token string) (*utils.DetectorV1, error) {
 	var detector utils.DetectorV1
-	err = json.Unmarshal(body, &detector)
-	if err != nil {
-		log.Fatalf("Can't load JSON, %v", err)
+	if err := fetch.GetJSON(ctx, client, fmt.Sprintf("%v/v1/detector/%v", api, detectorID), token, &detector); err != nil {
+		return nil, err
 	}
+	if detector.Sf_id == "" {
+		return nil, fmt.Errorf("Response of v1 API is not a detector")
+	}
+	return &detector, nil
+}
+
+// CreateDetectorV1 - function for generating detector from old version API.
+func CreateDetectorV1(f *hclwrite.File, detector *utils.DetectorV1, opts *utils.Options) (*hclwrite.Body, error) {
 
 	// wrapper around label
-	label := utils.LabelProc(detector.Sf_id)
+	label := opts.Label(detector.Sf_id, detector.Sf_detector)
+	// diagnostics of the detector
+	d := opts.Diagnoser(utils.KindDetector, detector.Sf_id)
+
+	// Constructs which need manual migration
+	MigrationProcV1(detector, d)
 
 	rootBody := f.Body()
 	detectorBlock := rootBody.AppendNewBlock("resource", []string{"signalfx_detector", label})
 	detectorBody := detectorBlock.Body()
-	detectorBody.SetAttributeValue("name", cty.StringVal(fmt.Sprintf("testV1-%s", detector.Sf_detector))) // TODO: Hardcoded to prevent self-destroy
+	detectorBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindDetector, detector.Sf_id, detector.Sf_detector)))
 	detectorBody.SetAttributeValue("description", cty.StringVal(detector.Sf_description))
-	detectorBody.SetAttributeValue("max_delay", cty.NumberIntVal(detector.Sf_jobMaxDelay))
-	detectorBody.SetAttributeTraversal("program_text", hcl.Traversal{
-		hcl.TraverseRoot{
-			Name: utils.ProgramTextProc(detector.Sf_programText),
-		},
-	})
+	if len(detector.Sf_memberOf) > 0 {
+		detectorBody.SetAttributeValue("teams", utils.StringListProc(detector.Sf_memberOf))
+	}
+	if maxDelay := detector.Sf_jobMaxDelay / 1000; maxDelay > 0 { // Convert to sec, 0 is `Auto`
+		detectorBody.SetAttributeValue("max_delay", cty.NumberIntVal(maxDelay))
+	}
+	if detector.Sf_timezone != "" {
+		detectorBody.SetAttributeValue("timezone", cty.StringVal(detector.Sf_timezone))
+	}
+	LabelResolutionsProc(detector.Sf_labelResolutions, "sf_labelResolutions", d)
+	utils.HeredocProc(detectorBody, "program_text", detector.Sf_programText)
 
 	// Rules processing
 	for _, rule := range detector.Sf_rules {
 		ruleBlock := detectorBody.AppendNewBlock("rule", nil)
 		ruleBody := ruleBlock.Body()
+		if rule.Readable != "" {
+			ruleBody.SetAttributeValue("description", cty.StringVal(rule.Readable))
+		}
 		ruleBody.SetAttributeValue("severity", cty.StringVal(rule.Severity))
 		ruleBody.SetAttributeValue("detect_label", cty.StringVal(rule.DetectLabel))
-		ruleBody.SetAttributeValue("description", cty.StringVal(rule.Readable))
-		ruleBody.SetAttributeValue("notifications", utils.NotificationProcV1(rule.Notifications))
-
+		if rule.Disabled {
+			ruleBody.SetAttributeValue("disabled", cty.True)
+		}
+		if rule.IsCustomizedMessage && rule.Parameterized != "" {
+			ruleBody.SetAttributeValue("parameterized_body", cty.StringVal(rule.Parameterized))
+		}
+		utils.TemplateListProc(ruleBody, "notifications", utils.NotificationProcV1(rule.Notifications, d, opts.Variables, label), ",")
 	}
 
-	return detectorBody
+	return detectorBody, nil
+}
+
+// v1DetectRegexp - detect() of SignalFlow v1 with condition instead of when()
+var v1DetectRegexp = regexp.MustCompile(`detect\(\s*[^w\s)]`)
 
+// MigrationProcV1 - report constructs of old version detector which must be migrated manually before terraform apply
+func MigrationProcV1(detector *utils.DetectorV1, d *utils.Diagnoser) {
+	if detector.Sf_signalflowVersion == 1 {
+		d.Unsupported("sf_signalflowVersion", "program is written in SignalFlow v1, check it with SignalFlow v2 syntax")
+		if v1DetectRegexp.MatchString(detector.Sf_programText) {
+			d.Unsupported("sf_programText", "detect() conditions must be wrapped with when() in SignalFlow v2, e.g. detect(when(A > 1))")
+		}
+	}
+	if len(detector.Sf_sourceSelectors) > 0 {
+		d.Dropped("sf_sourceSelectors", "source selectors must be moved to filter() of the program")
+	}
+	for _, rule := range detector.Sf_rules {
+		if rule.DetectLabel == "" {
+			d.Unsupported("sf_rules.detectLabel", "rule %q has no detect label, publish label of detect() must be set", rule.Readable)
+		}
+	}
 }
@@ -0,0 +1,93 @@
+package detectors
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/signalfx/signalfx-go/detector"
+)
+
+// testDetector - detector decoded from fixture of API response
+func testDetector(t *testing.T, path string) *detector.Detector {
+	t.Helper()
+	js, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Can't read fixture: %v", err)
+	}
+	d := &detector.Detector{}
+	if err := json.Unmarshal(js, d); err != nil {
+		t.Fatalf("Can't decode detector: %v", err)
+	}
+	return d
+}
+
+// attributeValue - formatted expression of the attribute, empty if it's not set
+func attributeValue(body *hclwrite.Body, name string) string {
+	attr := body.GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+	return string(hclwrite.Format(attr.Expr().BuildTokens(nil).Bytes()))
+}
+
+func TestRuleProc(t *testing.T) {
+	detector := testDetector(t, "testdata/detector.json")
+
+	tests := []struct {
+		name string
+		want map[string]string // attribute -> value, empty value for omitted attribute
+	}{
+		{
+			name: "rule with all attributes",
+			want: map[string]string{
+				"description":           `"CPU is above 90% for 5 minutes"`,
+				"severity":              `"Critical"`,
+				"detect_label":          `"CPU critical"`,
+				"disabled":              `true`,
+				"parameterized_subject": `"{{ruleSeverity}} Alert: {{{ruleName}}} ({{{detectorName}}})"`,
+				"parameterized_body":    `"{{#if anomalous}}\nRule \"{{{ruleName}}}\" triggered at {{timestamp}}.\n{{/if}}"`,
+				"runbook_url":           `"https://runbooks.example.com/cpu"`,
+				"tip":                   `"Check top processes"`,
+				"notifications":         `["Email,oncall@example.com"]`,
+			},
+		},
+		{
+			name: "rule with default attributes",
+			want: map[string]string{
+				"description":           "",
+				"severity":              `"Warning"`,
+				"detect_label":          `"CPU warning"`,
+				"disabled":              "",
+				"parameterized_subject": "",
+				"parameterized_body":    "",
+				"runbook_url":           "",
+				"tip":                   "",
+				"notifications":         `[]`,
+			},
+		},
+	}
+
+	if len(detector.Rules) != len(tests) {
+		t.Fatalf("fixture has %d rules, want %d", len(detector.Rules), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := hclwrite.NewEmptyFile().Body()
+			RuleProc(body, detector.Rules[i], nil, nil, "cpu_utilization")
+
+			for name, want := range tt.want {
+				if got := attributeValue(body, name); got != want {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+			// API names must not leak to terraform
+			for _, name := range []string{"detectLabel", "parameterizedBody", "parameterizedSubject", "runbookUrl"} {
+				if body.GetAttribute(name) != nil {
+					t.Errorf("API field %s is written as attribute", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package eventfeed
+
+import (
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Chart - function for generating event feed chart
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
+
+	// wrapper around label
+	label := opts.Label(chart.Id, chart.Name)
+	// diagnostics of the chart
+	d := opts.Diagnoser(utils.KindChart, chart.Id)
+
+	d.UnsupportedChartFields(chart, utils.ChartTags)
+
+	rootBody := f.Body()
+	chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
+	chartBody := chartBlock.Body()
+	chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
+	chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
+	utils.HeredocProc(chartBody, "program_text", chart.ProgramText)
+
+	// Time range processing
+	if t := chart.Options.Time; t != nil {
+		utils.TimeRangeProc(chartBody, "options.time", t.Type, t.Range, t.Start, t.End, d)
+	}
+	chartBody.AppendNewline()
+	return chartBody, nil
+}
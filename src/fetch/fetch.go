@@ -0,0 +1,174 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+	"github.com/signalfx/signalfx-go/dashboard"
+	"github.com/signalfx/signalfx-go/dashboard_group"
+)
+
+// DefaultWorkers - default amount of concurrent requests
+const DefaultWorkers = 4
+
+var (
+	// ErrNotFound - object doesn't exist in the API
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized - token is rejected by API
+	ErrUnauthorized = errors.New("unauthorized, check token and realm")
+)
+
+// API - raw access to the API, requests are cancelled with context and HTTP statuses are checked
+type API struct {
+	Client *http.Client
+	URL    string
+	Token  string
+}
+
+// DashboardCharts - fetch all charts of the dashboard once, see Charts
+func DashboardCharts(ctx context.Context, api *API, dashboard *dashboard.Dashboard, workers int) (map[string]*utils.Chart, error) {
+	ids := make([]string, 0, len(dashboard.Charts))
+	for _, v := range dashboard.Charts {
+		ids = append(ids, v.ChartId)
+	}
+	return Charts(ctx, api, ids, workers)
+}
+
+// Charts - fetch charts by id concurrently with at most workers requests at once,
+// every chart is fetched once, the first error or cancelled context stops the fetch
+func Charts(ctx context.Context, api *API, ids []string, workers int) (map[string]*utils.Chart, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	charts := map[string]*utils.Chart{}
+	queue := make(chan string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				chart, err := api.Chart(ctx, id)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("Can't get chart %s: %w", id, err)
+				}
+				if err == nil {
+					charts[id] = chart
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := map[string]bool{}
+	for _, id := range ids {
+		mu.Lock()
+		stop := firstErr != nil || ctx.Err() != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return charts, nil
+}
+
+// Dashboard - fetch dashboard, ErrNotFound and ErrUnauthorized are returned for corresponding HTTP statuses
+func (a *API) Dashboard(ctx context.Context, id string) (*dashboard.Dashboard, error) {
+	var dashboard dashboard.Dashboard
+	if err := GetJSON(ctx, a.Client, fmt.Sprintf("%v/v2/dashboard/%v", a.URL, id), a.Token, &dashboard); err != nil {
+		return nil, err
+	}
+	if dashboard.Id == "" {
+		return nil, fmt.Errorf("Response of API is not a dashboard")
+	}
+	return &dashboard, nil
+}
+
+// DashboardGroup - fetch dashboard group, ErrNotFound and ErrUnauthorized are returned for corresponding HTTP statuses
+func (a *API) DashboardGroup(ctx context.Context, id string) (*dashboard_group.DashboardGroup, error) {
+	var group dashboard_group.DashboardGroup
+	if err := GetJSON(ctx, a.Client, fmt.Sprintf("%v/v2/dashboardgroup/%v", a.URL, id), a.Token, &group); err != nil {
+		return nil, err
+	}
+	if group.Id == "" {
+		return nil, fmt.Errorf("Response of API is not a dashboard group")
+	}
+	return &group, nil
+}
+
+// Chart - fetch chart, it's decoded from raw JSON because signalfx-go model misses some fields
+func (a *API) Chart(ctx context.Context, id string) (*utils.Chart, error) {
+	var chart utils.Chart
+	if err := GetJSON(ctx, a.Client, fmt.Sprintf("%v/v2/chart/%v", a.URL, id), a.Token, &chart); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckChart(&chart); err != nil {
+		return nil, fmt.Errorf("Response of API for chart %s: %v", id, err)
+	}
+	return &chart, nil
+}
+
+// GetJSON - fetch API object and decode it to v,
+// ErrNotFound and ErrUnauthorized are returned for corresponding HTTP statuses
+func GetJSON(ctx context.Context, client *http.Client, objectURL string, token string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", objectURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("X-SF-TOKEN", token)
+	response, err := client.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("Can't fetch data from API %v, %w", objectURL, err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("Can't read body JSON, %v", err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	default:
+		return fmt.Errorf("Bad status %d from API %v: %s", response.StatusCode, objectURL, body)
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("Can't load JSON, %v", err)
+	}
+
+	return nil
+}
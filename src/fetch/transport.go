@@ -0,0 +1,223 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/signalfx/signalfx-go"
+)
+
+// errNoRetry - request body can't be sent again
+var errNoRetry = errors.New("Request body can't be replayed for retry")
+
+const (
+	// DefaultRetries - default amount of retries for failed request
+	DefaultRetries = 5
+	// DefaultRateLimit - default limit of requests per second
+	DefaultRateLimit = 10
+)
+
+// Config - API access settings
+type Config struct {
+	Retries    int           // amount of retries for failed request
+	MinBackoff time.Duration // delay before the first retry, doubled for every next retry
+	MaxBackoff time.Duration // max delay between retries
+	RateLimit  float64       // requests per second for all clients, 0 - unlimited
+	Timeout    time.Duration // timeout of every attempt including response body read, retries aren't bounded by it
+}
+
+// DefaultConfig - default API access settings
+func DefaultConfig() Config {
+	return Config{
+		Retries:    DefaultRetries,
+		MinBackoff: time.Second,
+		MaxBackoff: 30 * time.Second,
+		RateLimit:  DefaultRateLimit,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// NewHTTPClient - HTTP client with retries, exponential backoff and rate limit,
+// the same client has to be used for all requests to share rate limit.
+// Client has no overall timeout, Config.Timeout is applied to every attempt by Transport.
+func NewHTTPClient(config Config) *http.Client {
+	return &http.Client{
+		Transport: &Transport{
+			Base:    http.DefaultTransport,
+			Config:  config,
+			limiter: newLimiter(config.RateLimit),
+		},
+	}
+}
+
+// NewClient - SignalFx API client, all requests go through httpClient
+func NewClient(token string, api string, httpClient *http.Client) (*signalfx.Client, error) {
+	return signalfx.NewClient(token, signalfx.APIUrl(api), signalfx.HTTPClient(httpClient))
+}
+
+// Transport - http.RoundTripper with retries and rate limit.
+// Network errors, timed out attempts, HTTP 429 and 5xx responses are retried,
+// `Retry-After` header has priority over exponential backoff.
+type Transport struct {
+	Base http.RoundTripper
+	Config
+
+	limiter *limiter
+}
+
+// RoundTrip - execute request with retries
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := t.MinBackoff
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Every attempt has own timeout, it's cancelled when response body is closed
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if t.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, t.Timeout)
+		}
+
+		r := req.WithContext(attemptCtx)
+		if attempt > 0 && req.Body != nil {
+			// Body is consumed by previous attempt
+			if req.GetBody == nil {
+				cancel()
+				return nil, errNoRetry
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(r)
+		// Cancelled request isn't retried, timed out attempt is
+		if attempt >= t.Retries || ctx.Err() != nil || !retryable(resp, err) {
+			if resp == nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, err
+		}
+
+		delay := backoff
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			// Drain body to reuse connection
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			log.Printf("Request %s failed with status %d, retry in %v", req.URL.Path, resp.StatusCode, delay)
+		} else {
+			log.Printf("Request %s failed: %v, retry in %v", req.URL.Path, err, delay)
+		}
+		cancel()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > t.MaxBackoff {
+			backoff = t.MaxBackoff
+		}
+	}
+}
+
+// retryable - check if request failed with transient error,
+// every network error of the attempt is transient
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// cancelBody - response body which cancels context of the attempt on close
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close - close body and release context of the attempt
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryAfter - parse `Retry-After` header in seconds or HTTP date format
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// limiter - spreads requests evenly to keep requests per second limit
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newLimiter - create limiter, nil for unlimited rate
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait - block until the next request is allowed
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+   "bytes"
+   "encoding/json"
+   "fmt"
+   "io/ioutil"
+   "os"
+
+   "github.com/signalfx/signalfx-go/dashboard"
+   "github.com/signalfx/signalfx-go/dashboard_group"
+   "github.com/urfave/cli/v2"
+
+   "github.com/doctornkz/signalfx2terraform/src/converter"
+   "github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+// Convert - convert signalfx resources from JSON files without API calls
+func Convert(c *cli.Context) error {
+   conv, labels, err := newConverter(c)
+   if err != nil {
+      return err
+   }
+   r := conv.NewResult()
+
+   var chartList []*utils.Chart
+   charts := map[string]*utils.Chart{}
+   for _, path := range c.StringSlice("chart") {
+      objects, err := readObjects(path)
+      if err != nil {
+         return err
+      }
+      for _, js := range objects {
+         chart := &utils.Chart{}
+         if err := decodeObject(path, js, chart); err != nil {
+            return err
+         }
+         if err := utils.CheckChart(chart); err != nil {
+            return fmt.Errorf("Can't load chart from %s: %v", path, err)
+         }
+         if _, ok := charts[chart.Id]; !ok {
+            chartList = append(chartList, chart)
+         }
+         charts[chart.Id] = chart
+      }
+   }
+
+   // Dashboards of these groups refer to generated group resource
+   groups := map[string]*dashboard_group.DashboardGroup{}
+   for _, path := range c.StringSlice("dashboard-group") {
+      objects, err := readObjects(path)
+      if err != nil {
+         return err
+      }
+      for _, js := range objects {
+         group := &dashboard_group.DashboardGroup{}
+         if err := decodeObject(path, js, group); err != nil {
+            return err
+         }
+         groups[group.Id] = group
+         if err := conv.WriteDashboardGroup(r, group); err != nil {
+            return err
+         }
+      }
+   }
+
+   // Charts used by dashboards are written with them, others are standalone
+   used := map[string]bool{}
+   for _, path := range c.StringSlice("dashboard") {
+      objects, err := readObjects(path)
+      if err != nil {
+         return err
+      }
+      for _, js := range objects {
+         dashboard := &dashboard.Dashboard{}
+         if err := decodeObject(path, js, dashboard); err != nil {
+            return err
+         }
+
+         for _, v := range dashboard.Charts {
+            used[v.ChartId] = true
+         }
+         if err := conv.WriteDashboard(r, dashboard, charts, groups[dashboard.GroupId]); err != nil {
+            return err
+         }
+      }
+   }
+
+   for _, chart := range chartList {
+      if !used[chart.Id] {
+         if err := conv.WriteChart(r, chart); err != nil {
+            return err
+         }
+      }
+   }
+
+   for _, path := range c.StringSlice("detector") {
+      objects, err := readObjects(path)
+      if err != nil {
+         return err
+      }
+      for _, js := range objects {
+         if err := convertDetector(conv, r, path, js); err != nil {
+            return err
+         }
+      }
+   }
+
+   return writeOutput(c, r, labels)
+}
+
+// convertDetector - write detector from JSON object,
+// detectors from old version API have `sf_` prefixed fields
+func convertDetector(conv *converter.Converter, r *converter.Result, path string, js json.RawMessage) error {
+   fields := map[string]json.RawMessage{}
+   if err := decodeObject(path, js, &fields); err != nil {
+      return err
+   }
+   if _, v1 := fields["sf_id"]; v1 {
+      detector := &utils.DetectorV1{}
+      if err := decodeObject(path, js, detector); err != nil {
+         return err
+      }
+      return conv.WriteDetectorV1(r, detector)
+   }
+   detector := &utils.Detector{}
+   if err := decodeObject(path, js, detector); err != nil {
+      return err
+   }
+   return conv.WriteDetector(r, detector)
+}
+
+// stdin - content of STDIN, it can be read only once
+var stdin []byte
+
+// readObjects - read JSON objects from file or STDIN ("-"),
+// file contains single object, array of objects or search API result
+func readObjects(path string) ([]json.RawMessage, error) {
+   var data []byte
+   var err error
+
+   if path == "-" {
+      if stdin == nil {
+         stdin, err = ioutil.ReadAll(os.Stdin)
+      }
+      data = stdin
+   } else {
+      data, err = ioutil.ReadFile(path)
+   }
+   if err != nil {
+      return nil, fmt.Errorf("Can't read %s: %v", path, err)
+   }
+
+   data = bytes.TrimSpace(data)
+   if len(data) > 0 && data[0] == '[' {
+      var list []json.RawMessage
+      if err := decodeObject(path, data, &list); err != nil {
+         return nil, err
+      }
+      return list, nil
+   }
+
+   var search struct {
+      Results []json.RawMessage `json:"results"`
+   }
+   if err := decodeObject(path, data, &search); err != nil {
+      return nil, err
+   }
+   if search.Results != nil {
+      return search.Results, nil
+   }
+   return []json.RawMessage{data}, nil
+}
+
+// decodeObject - unmarshal JSON to object in the API response shape
+func decodeObject(path string, js []byte, v interface{}) error {
+   if err := json.Unmarshal(js, v); err != nil {
+      return fmt.Errorf("Can't load JSON from %s: %v", path, err)
+   }
+   return nil
+}
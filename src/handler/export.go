@@ -0,0 +1,58 @@
+package handler
+
+import (
+   "context"
+   "fmt"
+   "regexp"
+   "time"
+
+   "github.com/urfave/cli/v2"
+
+   "github.com/doctornkz/signalfx2terraform/src/converter"
+)
+
+// parseSince - parse date in "2006-01-02" or RFC3339 format to Unix time in ms
+func parseSince(s string) (int64, error) {
+   if s == "" {
+      return 0, nil
+   }
+   for _, layout := range []string{"2006-01-02", time.RFC3339} {
+      if t, err := time.Parse(layout, s); err == nil {
+         return t.UnixNano() / int64(time.Millisecond), nil
+      }
+   }
+   return 0, fmt.Errorf("Cannot parse date %s, use YYYY-MM-DD or RFC3339 format", s)
+}
+
+// Export - export all signalfx resources matching filters,
+// objects which can't be fetched or converted are skipped
+func Export(c *cli.Context) error {
+   filter := &converter.Filter{
+      Resources: c.StringSlice("resource"),
+      Tag:       c.String("tag"),
+      Team:      c.String("team"),
+   }
+   if name := c.String("name"); name != "" {
+      re, err := regexp.Compile(name)
+      if err != nil {
+         return fmt.Errorf("Wrong name regex: %v", err)
+      }
+      filter.Name = re
+   }
+   since, err := parseSince(c.String("modified-since"))
+   if err != nil {
+      return err
+   }
+   filter.Since = since
+
+   conv, labels, err := newConverter(c)
+   if err != nil {
+      return err
+   }
+
+   r := conv.NewResult()
+   if err := conv.Export(context.Background(), r, filter); err != nil {
+      return err
+   }
+   return writeOutput(c, r, labels)
+}
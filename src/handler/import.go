@@ -1,119 +1,172 @@
 package handler
 
 import (
+   "context"
+   "encoding/json"
    "fmt"
+   "io/ioutil"
    "log"
-   "github.com/hashicorp/hcl2/hclwrite"
-   "github.com/signalfx/signalfx-go"
+
    "github.com/urfave/cli/v2"
 
+   "github.com/doctornkz/signalfx2terraform/src/converter"
+   "github.com/doctornkz/signalfx2terraform/src/fetch"
    "github.com/doctornkz/signalfx2terraform/src/utils"
-   "github.com/doctornkz/signalfx2terraform/src/detectors"
-   "github.com/doctornkz/signalfx2terraform/src/timeseries"
-   "github.com/doctornkz/signalfx2terraform/src/list"
-   "github.com/doctornkz/signalfx2terraform/src/heatmap"
-   "github.com/doctornkz/signalfx2terraform/src/singlevalue"
-   "github.com/doctornkz/signalfx2terraform/src/text"
-)
-
-const (
-   // APIURL : default entrypoint for customer's requests
-   APIURL = "https://api.eu0.signalfx.com"
 )
 
 // Import - import signalfx resource
-func Import(c *cli.Context){
-   token := c.String("token")
+func Import(c *cli.Context) error {
+   conv, labels, err := newConverter(c)
+   if err != nil {
+      return err
+   }
+
+   ctx := context.Background()
+   r := conv.NewResult()
 
    if c.IsSet("dashboard") {
-      if dId := c.String("dashboard"); dId != "" {
-         fmt.Printf("%s",dashboardProcessor(dId, token))
-      } else {
-         log.Fatal("Dashboard Id not specified")
+      dId := c.String("dashboard")
+      if dId == "" {
+         return fmt.Errorf("Dashboard Id not specified")
+      }
+      if err := conv.AddDashboard(ctx, r, dId); err != nil {
+         return err
+      }
+   }
+
+   if c.IsSet("dashboard-group") {
+      gId := c.String("dashboard-group")
+      if gId == "" {
+         return fmt.Errorf("Dashboard group Id not specified")
+      }
+      if err := conv.AddDashboardGroup(ctx, r, gId); err != nil {
+         return err
       }
    }
 
    if c.IsSet("detector") {
-      if dId := c.String("detector"); dId != "" {
-         fmt.Printf("%s",detectorProcessor(dId, token))
-      } else {
-         log.Fatal("Detector Id not specified")
+      dId := c.String("detector")
+      if dId == "" {
+         return fmt.Errorf("Detector Id not specified")
+      }
+      if err := conv.AddDetector(ctx, r, dId); err != nil {
+         return err
       }
    }
+
+   return writeOutput(c, r, labels)
 }
 
-// dashboardProcessor - process dashboard import
-func dashboardProcessor(d string, t string) []byte {
+// newConverter - converter with output layout and label map from command line flags,
+// labels are returned to save label map after conversion
+func newConverter(c *cli.Context) (*converter.Converter, *utils.Labeler, error) {
+   options, err := converterOptions(c)
+   if err != nil {
+      return nil, nil, err
+   }
 
-   client, err := signalfx.NewClient(t, signalfx.APIUrl(APIURL))
+   labels := utils.NewLabeler()
+   if path := c.String("label-map"); path != "" {
+      labels, err = utils.LoadLabeler(path)
+      if err != nil {
+         return nil, nil, err
+      }
+   }
+   options = append(options, converter.Layout(c.String("layout")), converter.Labels(labels))
 
+   conv, err := converter.New(c.String("token"), options...)
    if err != nil {
-      log.Fatal("Something wrong with API client")
+      return nil, nil, err
    }
-   dashboard, err := client.GetDashboard(d)
+   return conv, labels, nil
+}
 
+// converterOptions - API access and naming settings from command line flags
+func converterOptions(c *cli.Context) ([]converter.Option, error) {
+   naming, err := utils.NewNamingPolicy(c.String("name-prefix"), c.String("name-suffix"), c.String("name-template"))
    if err != nil {
-      log.Printf("Dashboard error: %v", err)
-      log.Fatal("Can't fetch dashboard")
+      return nil, fmt.Errorf("Wrong naming template: %v", err)
    }
-   charts := dashboard.Charts
 
-   f := hclwrite.NewEmptyFile()
+   // All API requests share retries and rate limit
+   config := fetch.DefaultConfig()
+   config.Retries = c.Int("retries")
+   config.RateLimit = c.Float64("rate-limit")
+
+   return []converter.Option{
+      converter.Realm(c.String("realm")),
+      converter.APIURL(c.String("api-url")),
+      converter.Naming(naming),
+      converter.HTTPClient(fetch.NewHTTPClient(config)),
+      converter.Workers(c.Int("workers")),
+      converter.Variables(c.Bool("secret-variables"), c.Bool("credential-variables")),
+   }, nil
+}
 
-   utils.CreateDashboard(f, dashboard, client)
+// writeOutput - print files to STDOUT or write them to the output directory,
+// diagnostics are printed to STDERR or written to the report
+func writeOutput(c *cli.Context, r *converter.Result, labels *utils.Labeler) error {
+   if err := writeReport(c.String("report"), r.Diagnostics); err != nil {
+      return err
+   }
 
-   for _, v := range charts {
-      chart, err := client.GetChart(v.ChartId)
+   // Imported objects keep their id, changed names are applied to the original objects
+   if (c.Bool("import-blocks") || c.Bool("import-script")) &&
+      (c.String("name-prefix") != "" || c.String("name-suffix") != "" || c.String("name-template") != "") {
+      log.Printf("Warning: names are changed by --name-prefix, --name-suffix or --name-template, the first terraform apply renames imported objects")
+   }
 
-      if err != nil {
-         log.Printf("Chart error: %v", err)
-         log.Fatal("Can't get chart")
+   out := r.Files
+   out.Variables(r.Variables.List())
+   if c.Bool("import-blocks") {
+      out.ImportBlocks()
+   }
+   if c.Bool("import-script") {
+      if c.String("out-dir") == "" {
+         return fmt.Errorf("Import script can be generated only with output directory")
       }
+      out.ImportScript()
+   }
 
-      switch types := chart.Options.Type; types {
-      case "SingleValue":
-         singlevalue.Chart(f, chart)
-      case "Heatmap":
-         heatmap.Chart(f, chart)
-      case "TimeSeriesChart":
-         timeseries.Chart(f, chart)
-      case "List":
-         list.Chart(f, chart)
-
-      case "Text":
-         text.Chart(f, chart)
-      default:
-         continue
+   if dir := c.String("out-dir"); dir != "" {
+      if err := out.Write(dir, c.Bool("force")); err != nil {
+         return err
       }
-
+   } else {
+      fmt.Printf("%s", out.Bytes())
    }
-   return f.Bytes()
-}
-
-// detectorProcessor - process detector import
-func detectorProcessor(d string, t string) []byte {
-   client, err := signalfx.NewClient(t, signalfx.APIUrl(APIURL))
 
-   if err != nil {
-      log.Fatal("Something wrong with API client")
+   // Keep labels stable for the next export
+   if path := c.String("label-map"); path != "" {
+      if err := labels.Save(path); err != nil {
+         return err
+      }
    }
+   return nil
+}
 
-   detector, err := client.GetDetector(d)
-
-   if err != nil {
-      // Failover for V1 detector
-      // TODO: Implement reliable check
-      //log.Printf("Detector error: %v", err)
-      //log.Println("Can't fetch detector with V2 API, trying failover method...")
-      f := hclwrite.NewEmptyFile()
-
-      detectors.CreateDetectorV1(f, APIURL, d, t)
-
-      return f.Bytes()
+// writeReport - write diagnostics to JSON report file or print them to STDERR,
+// summary is always printed
+func writeReport(path string, diagnostics utils.Diagnostics) error {
+   if path != "" {
+      if diagnostics == nil {
+         diagnostics = utils.Diagnostics{}
+      }
+      js, err := json.MarshalIndent(diagnostics, "", "  ")
+      if err != nil {
+         return err
+      }
+      if err := ioutil.WriteFile(path, append(js, '\n'), 0644); err != nil {
+         return fmt.Errorf("Can't write report %s: %v", path, err)
+      }
    } else {
-      f := hclwrite.NewEmptyFile()
-      detectors.CreateDetector(f, detector)
+      for _, d := range diagnostics {
+         log.Printf("%s", d)
+      }
+   }
 
-      return f.Bytes()
+   if len(diagnostics) > 0 {
+      log.Printf("Diagnostics: %s, fix them manually before terraform apply", diagnostics.Summary())
    }
+   return nil
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+   "context"
+   "errors"
    "fmt"
    "log"
    "net/http"
@@ -8,20 +10,39 @@ import (
    "strings"
 
    "github.com/urfave/cli/v2"
+
+   "github.com/doctornkz/signalfx2terraform/src/converter"
+   "github.com/doctornkz/signalfx2terraform/src/fetch"
+   "github.com/doctornkz/signalfx2terraform/src/utils"
 )
 
-var token string
+var (
+   token   string
+   options []converter.Option
+
+   // errBadRequest - URL doesn't contain resource id
+   errBadRequest = errors.New("bad request")
+)
 
 // Webserver - creates a webserver
-func Webserver(c *cli.Context){
+func Webserver(c *cli.Context) error {
    port := c.String("port")
    address := c.String("address")
    bind := address + ":" + port
 
    token = c.String("token")
-
    if token == "" {
-      log.Fatal("No Signalfx Token provided")
+      return fmt.Errorf("No Signalfx Token provided")
+   }
+
+   var err error
+   options, err = converterOptions(c)
+   if err != nil {
+      return err
+   }
+   conv, err := converter.New(token, options...)
+   if err != nil {
+      return err
    }
 
    http.HandleFunc("/", handleRoot)
@@ -29,11 +50,12 @@ func Webserver(c *cli.Context){
    http.HandleFunc("/detector/", handler)
    http.HandleFunc("/api/metrics", handleMetrics)
 
-   fmt.Println("Starting server on " + bind)
+   fmt.Println("Starting server on " + bind + " using " + conv.APIURL())
 
    if err := http.ListenAndServe(bind, nil); err != nil {
-      log.Fatal("Cannot bind to " + bind, err)
+      return fmt.Errorf("Cannot bind to %s: %v", bind, err)
    }
+   return nil
 }
 
 // handleRoot - Handle root path
@@ -49,11 +71,22 @@ func handler(w http.ResponseWriter, r *http.Request) {
    // TODO: Improve logging
    log.Printf("New request from <%s> and User-agent <%s> and URL: <%s>", r.Header.Get("X-Forwarded-For"), r.Header.Get("User-Agent"), r.URL.String())
 
-   out, err := importResource(r.URL.String())
+   out, err := importResource(r.Context(), r.URL.String())
 
    if err != nil {
-      w.WriteHeader(http.StatusInternalServerError)
+      log.Printf("Request <%s> failed: %v", r.URL.String(), err)
+      switch {
+         case errors.Is(err, errBadRequest):
+            w.WriteHeader(http.StatusBadRequest)
+         case errors.Is(err, fetch.ErrNotFound):
+            w.WriteHeader(http.StatusNotFound)
+         case errors.Is(err, fetch.ErrUnauthorized):
+            w.WriteHeader(http.StatusUnauthorized)
+         default:
+            w.WriteHeader(http.StatusInternalServerError)
+      }
       fmt.Fprintln(w, err)
+      return
    }
 
    fmt.Fprintln(w, out)
@@ -61,17 +94,44 @@ func handler(w http.ResponseWriter, r *http.Request) {
 
 // importResource - returns the id for the resource to import
 // returns string with output and error
-func importResource(url string) (string, error) {
+func importResource(ctx context.Context, url string) (string, error) {
    split := strings.Split(url, "/")
 
+   // Labels are resolved per request to keep them independent from previous requests
+   conv, err := converter.New(token, append([]converter.Option{converter.Labels(utils.NewLabeler())}, options...)...)
+   if err != nil {
+      return "", err
+   }
+
+   var r *converter.Result
    switch i := split[1]; i {
       case "dashboard":
-         return string(dashboardProcessor(strings.Split(split[2], "?")[0], token)), nil
+         id := pathID(split, 2)
+         if id == "" {
+            return "", fmt.Errorf("Cannot find dashboard id in %s: %w", url, errBadRequest)
+         }
+         r, err = conv.ConvertDashboard(ctx, id)
       case "detector":
-         return string(detectorProcessor(strings.Split(split[3], "?")[0], token)), nil
+         id := pathID(split, 3)
+         if id == "" {
+            return "", fmt.Errorf("Cannot find detector id in %s: %w", url, errBadRequest)
+         }
+         r, err = conv.ConvertDetector(ctx, id)
       default:
-         return "", fmt.Errorf("Cannot import %s", i)
+         return "", fmt.Errorf("Cannot import %s: %w", i, errBadRequest)
+   }
+   if err != nil {
+      return "", err
+   }
+   return string(r.Files.Bytes()), nil
+}
+
+// pathID - id in segment i of the URL path without query, empty if the path is shorter
+func pathID(split []string, i int) string {
+   if len(split) <= i {
+      return ""
    }
+   return strings.Split(split[i], "?")[0]
 }
 
 // handleMetrics - print out string "up 1"
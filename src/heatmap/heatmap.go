@@ -3,18 +3,13 @@ package heatmap
 import (
    "github.com/doctornkz/signalfx2terraform/src/utils"
 
-   "github.com/hashicorp/hcl2/hcl"
    "github.com/hashicorp/hcl2/hclwrite"
-   "github.com/signalfx/signalfx-go/chart"
 
    "github.com/zclconf/go-cty/cty"
 )
 
 // Chart - function for generating heatmap chart
-func Chart(f *hclwrite.File, chart *chart.Chart) *hclwrite.Body {
-
-   // program_text wrapper
-   programText := utils.ProgramTextProc(chart.ProgramText)
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
 
    // tags wrapper
    tags := chart.Tags
@@ -23,31 +18,53 @@ func Chart(f *hclwrite.File, chart *chart.Chart) *hclwrite.Body {
    }
 
    // wrapper around label
-   label := utils.LabelProc(chart.Id)
+   label := opts.Label(chart.Id, chart.Name)
+   // diagnostics of the chart
+   d := opts.Diagnoser(utils.KindChart, chart.Id)
+
+   d.UnsupportedChartFields(chart, utils.ChartTags)
 
    rootBody := f.Body()
    chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
    chartBody := chartBlock.Body()
-   chartBody.SetAttributeValue("name", cty.StringVal(chart.Name))
+   chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
    chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
-   chartBody.SetAttributeTraversal("program_text", hcl.Traversal{
-      hcl.TraverseRoot{
-         Name: programText,
-      },
-   })
+   utils.HeredocProc(chartBody, "program_text", chart.ProgramText)
    chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
-   chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(utils.MaxDelayProc(chart)))
+   if maxDelay, ok := utils.MaxDelayProc(chart); ok {
+      chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(maxDelay))
+   }
+   if minDelay, ok := utils.MinDelayProc(chart); ok {
+      chartBody.SetAttributeValue("min_delay", cty.NumberIntVal(minDelay))
+   }
    chartBody.SetAttributeValue("group_by", utils.GroupByProc(chart))
+   if sortBy := utils.SortByProc(chart, d); sortBy != "" {
+      chartBody.SetAttributeValue("sort_by", cty.StringVal(sortBy))
+   }
+   if chart.Options.TimestampHidden {
+      chartBody.SetAttributeValue("hide_timestamp", cty.True)
+   }
 
    if chart.Options.ColorBy == "Range" {
-      utils.ColorRangeProc(chart, chartBody)
+      if err := utils.ColorRangeProc(chart, chartBody, d); err != nil {
+         return nil, err
+      }
    } else {
-      utils.ColorScale2Proc(chart, chartBody)
+      if err := utils.ColorScale2Proc(chart, chartBody); err != nil {
+         return nil, err
+      }
    }
 
-   chartBody.SetAttributeValue("minimum_resolution", cty.NumberIntVal(utils.MinResolutionProc(chart)))
-   chartBody.SetAttributeValue("disable_sampling", utils.DisableSamplingProc(chart))
+   if minResolution, ok := utils.MinResolutionProc(chart); ok {
+      chartBody.SetAttributeValue("minimum_resolution", cty.NumberIntVal(minResolution))
+   }
+   if utils.DisableSamplingProc(chart) {
+      chartBody.SetAttributeValue("disable_sampling", cty.True)
+   }
+   if timezone := utils.TimezoneProc(chart); timezone != "" {
+      chartBody.SetAttributeValue("timezone", cty.StringVal(timezone))
+   }
    chartBody.SetAttributeValue("refresh_interval", cty.NumberIntVal(utils.RefreshIntervalProc(chart)))
    chartBody.AppendNewline()
-   return chartBody
+   return chartBody, nil
 }
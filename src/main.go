@@ -7,7 +7,10 @@ import (
    "os"
 
    "github.com/urfave/cli/v2"
+   "github.com/doctornkz/signalfx2terraform/src/converter"
+   "github.com/doctornkz/signalfx2terraform/src/fetch"
    "github.com/doctornkz/signalfx2terraform/src/handler"
+   "github.com/doctornkz/signalfx2terraform/src/output"
 )
 
 var (
@@ -17,6 +20,123 @@ var (
    version          string
 )
 
+// tokenFlag - API token, required by commands which can't run without API
+func tokenFlag(required bool) cli.Flag {
+   return &cli.StringFlag{
+      Name: "token",
+      Aliases: []string{"t"},
+      Usage: "Signalfx token",
+      Required: required,
+      EnvVars: []string{"SIGNALFX_TOKEN"},
+   }
+}
+
+// apiFlags - API endpoint and access settings
+func apiFlags() []cli.Flag {
+   return []cli.Flag{
+      &cli.StringFlag{
+         Name: "realm",
+         Aliases: []string{"r"},
+         Usage: "Signalfx realm (eu0, us0, us1, us2, ...)",
+         Value: converter.DefaultRealm,
+         DefaultText: converter.DefaultRealm,
+         EnvVars: []string{"SIGNALFX_REALM"},
+      },
+      &cli.StringFlag{
+         Name: "api-url",
+         Usage: "Signalfx API URL, overrides realm",
+         EnvVars: []string{"SIGNALFX_API_URL"},
+      },
+      &cli.IntFlag{
+         Name: "workers",
+         Usage: "Amount of concurrent chart requests",
+         Value: fetch.DefaultWorkers,
+      },
+      &cli.IntFlag{
+         Name: "retries",
+         Usage: "Amount of retries for failed API requests (HTTP 429, 5xx, network errors)",
+         Value: fetch.DefaultRetries,
+      },
+      &cli.Float64Flag{
+         Name: "rate-limit",
+         Usage: "Limit of API requests per second, 0 - unlimited",
+         Value: fetch.DefaultRateLimit,
+      },
+   }
+}
+
+// namingFlags - naming policy of generated resources
+func namingFlags() []cli.Flag {
+   return []cli.Flag{
+      &cli.StringFlag{
+         Name: "name-prefix",
+         Usage: "Prefix for names of generated dashboards, charts, detectors and groups, e.g. 'test-' for copies of objects",
+      },
+      &cli.StringFlag{
+         Name: "name-suffix",
+         Usage: "Suffix for names of generated dashboards, charts, detectors and groups",
+      },
+      &cli.StringFlag{
+         Name: "name-template",
+         Usage: "Go template for names, e.g. '{{.Name}} ({{.Kind}})', overrides prefix and suffix",
+      },
+   }
+}
+
+// outputFlags - generated files, report and labels of commands which write terraform files
+func outputFlags() []cli.Flag {
+   return []cli.Flag{
+      &cli.StringFlag{
+         Name: "out-dir",
+         Aliases: []string{"o"},
+         Usage: "Write terraform files to directory instead of STDOUT",
+      },
+      &cli.StringFlag{
+         Name: "layout",
+         Usage: "Output files layout: resource, dashboard or single",
+         Value: output.LayoutResource,
+         DefaultText: output.LayoutResource,
+      },
+      &cli.BoolFlag{
+         Name: "force",
+         Aliases: []string{"f"},
+         Usage: "Overwrite existing files in output directory",
+      },
+      &cli.BoolFlag{
+         Name: "import-blocks",
+         Usage: "Generate terraform (>= 1.5) import blocks for imported resources",
+      },
+      &cli.BoolFlag{
+         Name: "import-script",
+         Usage: "Generate import.sh with terraform import commands, requires --out-dir",
+      },
+      &cli.StringFlag{
+         Name: "report",
+         Usage: "JSON file for conversion diagnostics (dropped, approximated and unsupported fields), default - STDERR",
+      },
+      &cli.BoolFlag{
+         Name: "secret-variables",
+         Usage: "Replace webhook secrets with sensitive terraform variables, declared in variables.tf",
+      },
+      &cli.BoolFlag{
+         Name: "credential-variables",
+         Usage: "Replace integration credential ids with terraform variables, declared in variables.tf",
+      },
+      &cli.StringFlag{
+         Name: "label-map",
+         Usage: "JSON file with object id to terraform label map, keeps labels stable between exports",
+      },
+   }
+}
+
+// flags - flags of the command joined in order of groups
+func flags(groups ...[]cli.Flag) []cli.Flag {
+   var all []cli.Flag
+   for _, group := range groups {
+      all = append(all, group...)
+   }
+   return all
+}
 
 func main() {
    app := &cli.App{
@@ -26,59 +146,131 @@ func main() {
          {
             Name: "import",
             Usage: "Import signalfx resources",
-            Flags: []cli.Flag{
-               &cli.StringFlag{
-                 Name: "token",
-                 Aliases: []string{"t"},
-                 Usage: "Signalfx token",
-                 Required: true,
+            Flags: flags(
+               []cli.Flag{
+                  tokenFlag(true),
+                  &cli.StringFlag{
+                     Name: "dashboard",
+                     Aliases: []string{"d"},
+                     Usage: "Signalfx dashboard id",
+                  },
+                  &cli.StringFlag{
+                     Name: "dashboard-group",
+                     Aliases: []string{"g"},
+                     Usage: "Signalfx dashboard group id, imports group with all its dashboards",
+                  },
+                  &cli.StringFlag{
+                     Name: "detector",
+                     Usage: "Signalfx detector id",
+                     Aliases: []string{"x"},
+                  },
                },
-               &cli.StringFlag{
-                  Name: "dashboard",
-                  Aliases: []string{"d"},
-                  Usage: "Signalfx dashboard id",
-               },
-               &cli.StringFlag{
-                  Name: "detector",
-                  Usage: "Signalfx detector id",
-                  Aliases: []string{"x"},
+               outputFlags(),
+               apiFlags(),
+               namingFlags(),
+            ),
+            Action: func(c *cli.Context) error {
+               return handler.Import(c)
+            },
+         },
+         {
+            Name: "export-all",
+            Usage: "Export all signalfx resources matching filters",
+            Flags: flags(
+               []cli.Flag{tokenFlag(true)},
+               apiFlags(),
+               namingFlags(),
+               []cli.Flag{
+                  &cli.StringSliceFlag{
+                     Name: "resource",
+                     Usage: "Resource types to export: dashboard-group, dashboard, detector",
+                     Value: cli.NewStringSlice("dashboard-group", "dashboard", "detector"),
+                  },
+                  &cli.StringFlag{
+                     Name: "name",
+                     Aliases: []string{"n"},
+                     Usage: "Export only resources with name matching regex",
+                  },
+                  &cli.StringFlag{
+                     Name: "tag",
+                     Usage: "Export only dashboards and detectors with tag",
+                  },
+                  &cli.StringFlag{
+                     Name: "team",
+                     Usage: "Export only dashboard groups and detectors of team id",
+                  },
+                  &cli.StringFlag{
+                     Name: "modified-since",
+                     Usage: "Export only resources modified since date (YYYY-MM-DD or RFC3339)",
+                  },
                },
+               outputFlags(),
+            ),
+            Action: func(c *cli.Context) error {
+               return handler.Export(c)
             },
+         },
+         {
+            Name: "convert",
+            Usage: "Convert signalfx resources from JSON files, use - for STDIN",
+            Flags: flags(
+               []cli.Flag{
+                  &cli.StringSliceFlag{
+                     Name: "dashboard-group",
+                     Aliases: []string{"g"},
+                     Usage: "JSON file with dashboard group",
+                  },
+                  &cli.StringSliceFlag{
+                     Name: "dashboard",
+                     Aliases: []string{"d"},
+                     Usage: "JSON file with dashboard, its charts are passed with --chart",
+                  },
+                  &cli.StringSliceFlag{
+                     Name: "chart",
+                     Aliases: []string{"c"},
+                     Usage: "JSON file with chart",
+                  },
+                  &cli.StringSliceFlag{
+                     Name: "detector",
+                     Aliases: []string{"x"},
+                     Usage: "JSON file with detector (v2 or v1 API)",
+                  },
+               },
+               outputFlags(),
+               namingFlags(),
+            ),
             Action: func(c *cli.Context) error {
-               handler.Import(c)
-               return nil
+               return handler.Convert(c)
             },
          },
          {
             Name: "webserver",
             Usage: "Create webserver to interact with signalfx resources",
-            Flags: []cli.Flag{
-               &cli.IntFlag{
-                 Name: "port",
-                 Aliases: []string{"p"},
-                 Usage: "Webserver port to bind",
-                 Value: 8080,
-                 DefaultText: "8080",
-                 EnvVars: []string{"PORT"},
-               },
-               &cli.StringFlag{
-                 Name: "address",
-                 Aliases: []string{"a"},
-                 Usage: "Webserver address to use",
-                 Value: "localhost",
-                 DefaultText: "localhost",
-                 EnvVars: []string{"ADDRESS"},
+            Flags: flags(
+               []cli.Flag{
+                  &cli.IntFlag{
+                     Name: "port",
+                     Aliases: []string{"p"},
+                     Usage: "Webserver port to bind",
+                     Value: 8080,
+                     DefaultText: "8080",
+                     EnvVars: []string{"PORT"},
+                  },
+                  &cli.StringFlag{
+                     Name: "address",
+                     Aliases: []string{"a"},
+                     Usage: "Webserver address to use",
+                     Value: "localhost",
+                     DefaultText: "localhost",
+                     EnvVars: []string{"ADDRESS"},
+                  },
+                  tokenFlag(false),
                },
-               &cli.StringFlag{
-                 Name: "token",
-                 Aliases: []string{"t"},
-                 Usage: "Signalfx token",
-                 EnvVars: []string{"SIGNALFX_TOKEN"},
-               },
-            },
+               apiFlags(),
+               namingFlags(),
+            ),
             Action: func(c *cli.Context) error {
-               handler.Webserver(c)
-               return nil
+               return handler.Webserver(c)
             },
          },
       },
@@ -0,0 +1,247 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/doctornkz/signalfx2terraform/src/utils"
+)
+
+const (
+	// LayoutResource - one file per resource
+	LayoutResource = "resource"
+	// LayoutDashboard - one file per dashboard and one file with all its charts
+	LayoutDashboard = "dashboard"
+	// LayoutSingle - all resources in one file
+	LayoutSingle = "single"
+
+	// singleFile - file name for LayoutSingle
+	singleFile = "main.tf"
+	// importsFile - file name for import blocks
+	importsFile = "imports.tf"
+	// importScript - file name for terraform import script
+	importScript = "import.sh"
+	// variablesFile - file name for variables
+	variablesFile = "variables.tf"
+)
+
+// Files - set of terraform files, resources are distributed by layout
+type Files struct {
+	layout string
+	order  []string                  // file names in order of creation
+	files  map[string]*hclwrite.File // file name -> file
+	keys   map[string]string         // object key -> file name
+	raw    map[string][]byte         // file name -> content of non-HCL file
+
+	imports []importEntry
+}
+
+// importEntry - existing SignalFx object managed by generated resource
+type importEntry struct {
+	resource string
+	label    string
+	id       string
+}
+
+// New - create empty set of files with layout
+func New(layout string) (*Files, error) {
+	switch layout {
+	case LayoutResource, LayoutDashboard, LayoutSingle:
+	default:
+		return nil, fmt.Errorf("Unknown layout %s, use %s, %s or %s", layout, LayoutResource, LayoutDashboard, LayoutSingle)
+	}
+	return &Files{
+		layout: layout,
+		files:  map[string]*hclwrite.File{},
+		keys:   map[string]string{},
+		raw:    map[string][]byte{},
+	}, nil
+}
+
+// DashboardGroup - file for dashboard group resource
+func (o *Files) DashboardGroup(id string, name string) *hclwrite.File {
+	return o.file("dashboard_group", id, name)
+}
+
+// Dashboard - file for dashboard resource
+func (o *Files) Dashboard(id string, name string) *hclwrite.File {
+	return o.file("dashboard", id, name)
+}
+
+// Chart - file for chart resource of dashboard
+func (o *Files) Chart(dashboardID string, dashboardName string, id string, name string) *hclwrite.File {
+	if o.layout == LayoutDashboard {
+		return o.file("charts", dashboardID, dashboardName)
+	}
+	return o.file("chart", id, name)
+}
+
+// Detector - file for detector resource
+func (o *Files) Detector(id string, name string) *hclwrite.File {
+	return o.file("detector", id, name)
+}
+
+// Import - register SignalFx object id of the generated resource
+func (o *Files) Import(resource string, label string, id string) {
+	o.imports = append(o.imports, importEntry{resource: resource, label: label, id: id})
+}
+
+// ImportBlocks - add terraform (>= 1.5) import blocks for all registered resources
+func (o *Files) ImportBlocks() {
+	if len(o.imports) == 0 {
+		return
+	}
+	name := importsFile
+	if o.layout == LayoutSingle {
+		name = singleFile
+	}
+	body := o.create(name).Body()
+	for _, i := range o.imports {
+		importBody := body.AppendNewBlock("import", nil).Body()
+		importBody.SetAttributeTraversal("to", hcl.Traversal{
+			hcl.TraverseRoot{Name: i.resource},
+			hcl.TraverseAttr{Name: i.label},
+		})
+		importBody.SetAttributeValue("id", cty.StringVal(i.id))
+	}
+	body.AppendNewline()
+}
+
+// Variables - add variable blocks for generated variables,
+// sensitive variables have no default and must be set before terraform apply
+func (o *Files) Variables(variables []*utils.Variable) {
+	if len(variables) == 0 {
+		return
+	}
+	name := variablesFile
+	if o.layout == LayoutSingle {
+		name = singleFile
+	}
+	body := o.create(name).Body()
+	for _, v := range variables {
+		variableBody := body.AppendNewBlock("variable", []string{v.Name}).Body()
+		variableBody.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+		variableBody.SetAttributeValue("description", cty.StringVal(v.Description))
+		if v.Default != "" {
+			variableBody.SetAttributeValue("default", cty.StringVal(v.Default))
+		}
+		if v.Sensitive {
+			variableBody.SetAttributeValue("sensitive", cty.True)
+		}
+	}
+	body.AppendNewline()
+}
+
+// ImportScript - add shell script with `terraform import` commands for all registered resources
+func (o *Files) ImportScript() {
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/sh\nset -e\n\n")
+	for _, i := range o.imports {
+		fmt.Fprintf(&buf, "terraform import %s.%s '%s'\n", i.resource, i.label, i.id)
+	}
+	o.raw[importScript] = buf.Bytes()
+	o.order = append(o.order, importScript)
+}
+
+// Names - file names in order of creation
+func (o *Files) Names() []string {
+	return o.order
+}
+
+// Content - content of the file with name, nil for unknown file
+func (o *Files) Content(name string) []byte {
+	if content, ok := o.raw[name]; ok {
+		return content
+	}
+	if f, ok := o.files[name]; ok {
+		return f.Bytes()
+	}
+	return nil
+}
+
+// Bytes - content of all terraform files concatenated in order of creation
+func (o *Files) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, name := range o.order {
+		if f, ok := o.files[name]; ok {
+			buf.Write(f.Bytes())
+		}
+	}
+	return buf.Bytes()
+}
+
+// Write - write files to directory,
+// existing files are overwritten only with overwrite flag
+func (o *Files) Write(dir string, overwrite bool) error {
+	if !overwrite {
+		var existing []string
+		for _, name := range o.order {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				existing = append(existing, name)
+			}
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("Files already exist in %s: %s", dir, strings.Join(existing, ", "))
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range o.order {
+		if content, ok := o.raw[name]; ok {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), o.files[name].Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// file - find or create file for object with id,
+// file name is built from kind and object name, collisions get numeric suffix
+func (o *Files) file(kind string, id string, name string) *hclwrite.File {
+	if o.layout == LayoutSingle {
+		return o.create(singleFile)
+	}
+
+	key := kind + "/" + id
+	if fileName, ok := o.keys[key]; ok {
+		return o.files[fileName]
+	}
+
+	slug := utils.Slugify(name)
+	if slug == "" {
+		slug = utils.Slugify(id)
+	}
+	fileName := fmt.Sprintf("%s_%s.tf", kind, slug)
+	for i := 2; o.files[fileName] != nil; i++ {
+		fileName = fmt.Sprintf("%s_%s_%d.tf", kind, slug, i)
+	}
+	o.keys[key] = fileName
+	return o.create(fileName)
+}
+
+// create - find or create file with name
+func (o *Files) create(name string) *hclwrite.File {
+	if f, ok := o.files[name]; ok {
+		return f
+	}
+	f := hclwrite.NewEmptyFile()
+	o.files[name] = f
+	o.order = append(o.order, name)
+	return f
+}
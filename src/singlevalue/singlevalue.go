@@ -2,57 +2,77 @@ package singlevalue
 
 import (
    "github.com/doctornkz/signalfx2terraform/src/utils"
-   "github.com/hashicorp/hcl2/hcl"
    "github.com/hashicorp/hcl2/hclwrite"
-   "github.com/signalfx/signalfx-go/chart"
 
    "github.com/zclconf/go-cty/cty"
 )
 
 // Chart - function for generating single value chart
-func Chart(f *hclwrite.File, chart *chart.Chart) *hclwrite.Body {
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
 
-   // program_text wrapper
-   programText := utils.ProgramTextProc(chart.ProgramText)
-   // group_by wrapper
-   groupBy := chart.Options.GroupBy
-   if groupBy == nil {
-      groupBy = []string{}
-   }
-   // tags wrapper
-   tags := chart.Tags
-   if tags == nil {
-      tags = []string{}
-   }
    // wrapper around label
-   label := utils.LabelProc(chart.Id)
+   label := opts.Label(chart.Id, chart.Name)
+   // diagnostics of the chart
+   d := opts.Diagnoser(utils.KindChart, chart.Id)
+
+   d.UnsupportedChartFields(chart, utils.ChartGroupBy, utils.ChartMinResolution, utils.ChartDisableSampling)
 
    rootBody := f.Body()
    chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
    chartBody := chartBlock.Body()
-   chartBody.SetAttributeValue("name", cty.StringVal(chart.Name))
+   chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
    chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
-   chartBody.SetAttributeTraversal("program_text", hcl.Traversal{
-      hcl.TraverseRoot{
-         Name: programText,
-      },
-   })
-   chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
-   chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(utils.MaxDelayProc(chart)))
+   utils.HeredocProc(chartBody, "program_text", chart.ProgramText)
+   if len(chart.Tags) > 0 {
+      chartBody.SetAttributeValue("tags", utils.StringListProc(chart.Tags))
+   }
+   if chart.Options.UnitPrefix != "" {
+      chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
+   }
+   if maxDelay, ok := utils.MaxDelayProc(chart); ok {
+      chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(maxDelay))
+   }
+   if minDelay, ok := utils.MinDelayProc(chart); ok {
+      chartBody.SetAttributeValue("min_delay", cty.NumberIntVal(minDelay))
+   }
+   if timezone := utils.TimezoneProc(chart); timezone != "" {
+      chartBody.SetAttributeValue("timezone", cty.StringVal(timezone))
+   }
+
+   if chart.Options.MaximumPrecision != nil {
+      chartBody.SetAttributeValue("max_precision", cty.NumberIntVal(int64(*chart.Options.MaximumPrecision)))
+   }
+   if chart.Options.ShowSparkLine {
+      chartBody.SetAttributeValue("show_spark_line", cty.True)
+   }
+   // hide_timestamp is the name of this option in table chart only
+   if chart.Options.TimestampHidden {
+      chartBody.SetAttributeValue("is_timestamp_hidden", cty.True)
+   }
 
    cby := chart.Options.ColorBy
-   chartBody.SetAttributeValue("color_by", cty.StringVal(cby))
+   if cby != "" {
+      chartBody.SetAttributeValue("color_by", cty.StringVal(cby))
+   }
 
    if cby == "Scale" {
-      utils.ColorScale2Proc(chart, chartBody)
+      if err := utils.ColorScale2Proc(chart, chartBody); err != nil {
+         return nil, err
+      }
    }
 
-   chartBody.SetAttributeValue("refresh_interval", cty.NumberIntVal(utils.RefreshIntervalProc(chart)))
+   if refreshInterval := utils.RefreshIntervalProc(chart); refreshInterval > 0 {
+      chartBody.SetAttributeValue("refresh_interval", cty.NumberIntVal(refreshInterval))
+   }
 
    // create viz_options
-   utils.GetVizOptions(chart, chartBody)
+   if err := utils.GetVizOptions(chart, chartBody); err != nil {
+      return nil, err
+   }
 
-   chartBody.SetAttributeValue("secondary_visualization", cty.StringVal(chart.Options.SecondaryVisualization))
+   if chart.Options.SecondaryVisualization != "" {
+      chartBody.SetAttributeValue("secondary_visualization", cty.StringVal(chart.Options.SecondaryVisualization))
+   }
    chartBody.AppendNewline()
-   return chartBody
+   return chartBody, nil
 }
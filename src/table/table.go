@@ -0,0 +1,57 @@
+package table
+
+import (
+   "github.com/doctornkz/signalfx2terraform/src/utils"
+   "github.com/hashicorp/hcl2/hclwrite"
+   "github.com/zclconf/go-cty/cty"
+)
+
+// Chart - function for generating table chart
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
+
+   // wrapper around label
+   label := opts.Label(chart.Id, chart.Name)
+   // diagnostics of the chart
+   d := opts.Diagnoser(utils.KindChart, chart.Id)
+
+   d.UnsupportedChartFields(chart, utils.ChartTags, utils.ChartMinResolution, utils.ChartTimezone)
+
+   rootBody := f.Body()
+   chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
+   chartBody := chartBlock.Body()
+   chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
+   chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
+
+   utils.HeredocProc(chartBody, "program_text", chart.ProgramText)
+   if utils.DisableSamplingProc(chart) {
+      chartBody.SetAttributeValue("disable_sampling", cty.True)
+   }
+   if chart.Options.UnitPrefix != "" {
+      chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
+   }
+   if maxDelay, ok := utils.MaxDelayProc(chart); ok {
+      chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(maxDelay))
+   }
+   if minDelay, ok := utils.MinDelayProc(chart); ok {
+      chartBody.SetAttributeValue("min_delay", cty.NumberIntVal(minDelay))
+   }
+   if refreshInterval := utils.RefreshIntervalProc(chart); refreshInterval > 0 {
+      chartBody.SetAttributeValue("refresh_interval", cty.NumberIntVal(refreshInterval))
+   }
+   if chart.Options.TimestampHidden {
+      chartBody.SetAttributeValue("hide_timestamp", cty.True)
+   }
+
+   if len(chart.Options.GroupBy) > 0 {
+      chartBody.SetAttributeValue("group_by", utils.GroupByProc(chart))
+   }
+
+   // legend_options_fields
+   utils.GetLegendOptionsBlock(chart, chartBody, d)
+
+   // create viz_options, table chart doesn't have plot type and axis
+   utils.ShortVizProc(chartBody, chart.Options.PublishLabelOptions, "options.publishLabelOptions", d)
+
+   chartBody.AppendNewline()
+   return chartBody, nil
+}
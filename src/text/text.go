@@ -3,22 +3,20 @@ package text
 import (
 	"github.com/doctornkz/signalfx2terraform/src/utils"
 	"github.com/hashicorp/hcl2/hclwrite"
-	"github.com/signalfx/signalfx-go/chart"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // Chart - function for generating text chart
-func Chart(f *hclwrite.File, chart *chart.Chart) *hclwrite.Body {
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
 
 	// wrapper around label
-	label := utils.LabelProc(chart.Id)
+	label := opts.Label(chart.Id, chart.Name)
 
 	rootBody := f.Body()
 	chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
 	chartBody := chartBlock.Body()
-	chartBody.SetAttributeValue("name", cty.StringVal(chart.Name))
+	chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
 	chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
-	chartBody.SetAttributeValue("markdown", cty.StringVal(chart.Options.Markdown))
-	chartBody.AppendNewline()
-	return chartBody
+	utils.HeredocProc(chartBody, "markdown", chart.Options.Markdown)
+	return chartBody, nil
 }
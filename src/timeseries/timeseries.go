@@ -2,83 +2,109 @@ package timeseries
 
 import (
    "github.com/doctornkz/signalfx2terraform/src/utils"
-   "github.com/hashicorp/hcl2/hcl"
    "github.com/hashicorp/hcl2/hclwrite"
-   "github.com/signalfx/signalfx-go/chart"
    "github.com/zclconf/go-cty/cty"
 )
 
 // Chart - function for generating time series chart
-func Chart(f *hclwrite.File, chart *chart.Chart) *hclwrite.Body {
+func Chart(f *hclwrite.File, chart *utils.Chart, opts *utils.Options) (*hclwrite.Body, error) {
 
-   // program_text wrapper
-   programText := utils.ProgramTextProc(chart.ProgramText)
-   // group_by wrapper
-   groupBy := chart.Options.GroupBy
-   if groupBy == nil {
-      groupBy = []string{}
-   }
-   // tags wrapper
-   tags := chart.Tags
-   if tags == nil {
-      tags = []string{}
-   }
    // wrapper around label
-   label := utils.LabelProc(chart.Id)
+   label := opts.Label(chart.Id, chart.Name)
+   // diagnostics of the chart
+   d := opts.Diagnoser(utils.KindChart, chart.Id)
+
+   d.UnsupportedChartFields(chart, utils.ChartGroupBy)
+   if len(chart.Options.Axes) > 2 {
+      d.Dropped("options.axes", "only left and right axes are converted")
+   }
 
    rootBody := f.Body()
    chartBlock := rootBody.AppendNewBlock("resource", []string{utils.Type[chart.Options.Type], label})
    chartBody := chartBlock.Body()
-   chartBody.SetAttributeValue("name", cty.StringVal(chart.Name))
+   chartBody.SetAttributeValue("name", cty.StringVal(opts.Name(utils.KindChart, chart.Id, chart.Name)))
    chartBody.SetAttributeValue("description", cty.StringVal(chart.Description))
-   chartBody.SetAttributeValue("plot_type", cty.StringVal(chart.Options.DefaultPlotType))
+   if chart.Options.DefaultPlotType != "" {
+      chartBody.SetAttributeValue("plot_type", cty.StringVal(chart.Options.DefaultPlotType))
+   }
    chartBody.SetAttributeValue("stacked", cty.BoolVal(chart.Options.Stacked))
    chartBody.SetAttributeValue("axes_include_zero", cty.BoolVal(chart.Options.IncludeZero))
+   if chart.Options.AxisPrecision != nil {
+      chartBody.SetAttributeValue("axes_precision", cty.NumberIntVal(int64(*chart.Options.AxisPrecision)))
+   }
+   if len(chart.Tags) > 0 {
+      chartBody.SetAttributeValue("tags", utils.StringListProc(chart.Tags))
+   }
+   if chart.Options.ShowEventLines {
+      chartBody.SetAttributeValue("show_event_lines", cty.True)
+   }
+   if (chart.Options.LineChartOptions != nil && chart.Options.LineChartOptions.ShowDataMarkers) ||
+      (chart.Options.AreaChartOptions != nil && chart.Options.AreaChartOptions.ShowDataMarkers) {
+      chartBody.SetAttributeValue("show_data_markers", cty.True)
+   }
 
-   // Histograms processing
-   if chart.Options.HistogramChartOptions != nil {
-      histogramOptionsBlock := chartBody.AppendNewBlock("histogram_options", nil)
-      histogramOptionsBody := histogramOptionsBlock.Body()
-      histogramOptionsBody.SetAttributeValue("color_theme", cty.StringVal(utils.Color[*chart.Options.HistogramChartOptions.ColorThemeIndex]))
+   // Axes processing, API has left axis first
+   for i, name := range []string{"axis_left", "axis_right"} {
+      if i < len(chart.Options.Axes) {
+         utils.AxisProc(chartBody, name, chart.Options.Axes[i])
+      }
+   }
+
+   // Histograms processing, color theme is the only histogram option
+   if chart.Options.HistogramChartOptions != nil && chart.Options.HistogramChartOptions.ColorThemeIndex != nil {
+      colorTheme, ok := utils.Color[*chart.Options.HistogramChartOptions.ColorThemeIndex]
+      if ok {
+         histogramOptionsBlock := chartBody.AppendNewBlock("histogram_options", nil)
+         histogramOptionsBody := histogramOptionsBlock.Body()
+         histogramOptionsBody.SetAttributeValue("color_theme", cty.StringVal(colorTheme))
+      } else {
+         d.Dropped("options.histogramChartOptions.colorThemeIndex", "unknown color theme %d", *chart.Options.HistogramChartOptions.ColorThemeIndex)
+      }
    }
 
    // legend_options_fields
-   utils.GetLegendOptionsBlock(chart, chartBody)
+   utils.GetLegendOptionsBlock(chart, chartBody, d)
 
    // create viz_options
-   utils.GetVizOptions(chart, chartBody)
-
+   if err := utils.GetVizOptions(chart, chartBody); err != nil {
+      return nil, err
+   }
 
    // create event_options
-   utils.EventProc(chart, chartBody)
+   if err := utils.EventProc(chart, chartBody); err != nil {
+      return nil, err
+   }
    // chartBody.SetAttributeValue("event_options", utils.EventProc(chart))
 
-   chartBody.SetAttributeTraversal("program_text", hcl.Traversal{
-      hcl.TraverseRoot{
-         Name: programText,
-      },
-   })
+   utils.HeredocProc(chartBody, "program_text", chart.ProgramText)
 
-   chartBody.SetAttributeValue("disable_sampling", utils.DisableSamplingProc(chart))
-   chartBody.SetAttributeValue("minimum_resolution", cty.NumberIntVal(utils.MinResolutionProc(chart)))
-   chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
-   chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(utils.MaxDelayProc(chart)))
-   chartBody.SetAttributeValue("color_by", cty.StringVal(chart.Options.ColorBy))
-   chartBody.SetAttributeValue("on_chart_legend_dimension", utils.OnChartLegendProc(chart))
+   if utils.DisableSamplingProc(chart) {
+      chartBody.SetAttributeValue("disable_sampling", cty.True)
+   }
+   if minResolution, ok := utils.MinResolutionProc(chart); ok {
+      chartBody.SetAttributeValue("minimum_resolution", cty.NumberIntVal(minResolution))
+   }
+   if chart.Options.UnitPrefix != "" {
+      chartBody.SetAttributeValue("unit_prefix", cty.StringVal(chart.Options.UnitPrefix))
+   }
+   if maxDelay, ok := utils.MaxDelayProc(chart); ok {
+      chartBody.SetAttributeValue("max_delay", cty.NumberIntVal(maxDelay))
+   }
+   if minDelay, ok := utils.MinDelayProc(chart); ok {
+      chartBody.SetAttributeValue("min_delay", cty.NumberIntVal(minDelay))
+   }
+   if timezone := utils.TimezoneProc(chart); timezone != "" {
+      chartBody.SetAttributeValue("timezone", cty.StringVal(timezone))
+   }
+   if chart.Options.ColorBy != "" {
+      chartBody.SetAttributeValue("color_by", cty.StringVal(chart.Options.ColorBy))
+   }
+   if dimension := utils.OnChartLegendProc(chart); dimension != "" {
+      chartBody.SetAttributeValue("on_chart_legend_dimension", cty.StringVal(dimension))
+   }
    // Time range processing
-   if chart.Options.Time != nil { // Checking Time structure against nil
-
-      if chart.Options.Time.Type == "relative" {
-         /* Convert to sec, we have different format here:
-         See details here: https://github.com/terraform-providers/terraform-provider-signalfx/issues/55
-         */
-         chartBody.SetAttributeValue("time_range", cty.NumberIntVal(*chart.Options.Time.Range/1000))
-      }
-
-      if chart.Options.Time.Type == "absolute" {
-         chartBody.SetAttributeValue("start_time", cty.NumberIntVal(*chart.Options.Time.Start/1000))
-         chartBody.SetAttributeValue("end_time", cty.NumberIntVal(*chart.Options.Time.End/1000))
-      }
+   if t := chart.Options.Time; t != nil {
+      utils.TimeRangeProc(chartBody, "options.time", t.Type, t.Range, t.Start, t.End, d)
    }
-   return chartBody
+   return chartBody, nil
 }
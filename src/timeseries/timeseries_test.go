@@ -0,0 +1,41 @@
+package timeseries
+
+import (
+   "encoding/json"
+   "testing"
+
+   "github.com/doctornkz/signalfx2terraform/src/utils"
+   "github.com/hashicorp/hcl2/hclwrite"
+)
+
+// Values of program options are covered in utils, here only auto options must be omitted
+func TestChartProgramOptions(t *testing.T) {
+   tests := []struct {
+      name    string
+      options string
+      written bool
+   }{
+      {"auto program options", `{"maxDelay": 0, "minDelay": null, "minimumResolution": null, "disableSampling": false}`, false},
+      {"set program options", `{"maxDelay": 15000, "minDelay": 5000, "minimumResolution": 60000, "disableSampling": true, "timezone": "UTC"}`, true},
+   }
+
+   for _, tt := range tests {
+      t.Run(tt.name, func(t *testing.T) {
+         c := &utils.Chart{}
+         js := `{"id": "A", "name": "a", "programText": "A = data('cpu').publish()", "options": {"type": "TimeSeriesChart", "programOptions": ` + tt.options + `}}`
+         if err := json.Unmarshal([]byte(js), c); err != nil {
+            t.Fatalf("Can't decode chart: %v", err)
+         }
+         body, err := Chart(hclwrite.NewEmptyFile(), c, &utils.Options{})
+         if err != nil {
+            t.Fatalf("Chart() error: %v", err)
+         }
+
+         for _, name := range []string{"max_delay", "min_delay", "minimum_resolution", "disable_sampling", "timezone"} {
+            if written := body.GetAttribute(name) != nil; written != tt.written {
+               t.Errorf("%s written = %v, want %v", name, written, tt.written)
+            }
+         }
+      })
+   }
+}
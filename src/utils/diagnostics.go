@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity - how the field is reflected in generated resource
+type Severity string
+
+const (
+	// SeverityDropped - field is ignored, generated resource doesn't have it
+	SeverityDropped Severity = "dropped"
+	// SeverityApproximated - field is converted to the closest supported value
+	SeverityApproximated Severity = "approximated"
+	// SeverityUnsupported - value isn't supported by converter or terraform provider, it's skipped
+	SeverityUnsupported Severity = "unsupported"
+)
+
+// Severities - all severities in order of importance
+var Severities = []Severity{SeverityUnsupported, SeverityDropped, SeverityApproximated}
+
+// Diagnostic - part of SignalFx object which can't be converted as is
+type Diagnostic struct {
+	Resource string   `json:"resource"`        // kind and id of the object, e.g. `chart Dw1xyzAYAAA`
+	Field    string   `json:"field,omitempty"` // API field of the object, e.g. `options.axes`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String - human readable diagnostic
+func (d Diagnostic) String() string {
+	if d.Field == "" {
+		return fmt.Sprintf("%s %s: %s", d.Severity, d.Resource, d.Message)
+	}
+	return fmt.Sprintf("%s %s %s: %s", d.Severity, d.Resource, d.Field, d.Message)
+}
+
+// Diagnostics - diagnostics collected during conversion
+type Diagnostics []Diagnostic
+
+// Add - add diagnostic for field of object of kind with id
+func (d *Diagnostics) Add(kind string, id string, field string, severity Severity, format string, args ...interface{}) {
+	*d = append(*d, Diagnostic{
+		Resource: fmt.Sprintf("%s %s", kind, id),
+		Field:    field,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Count - amount of diagnostics with severity
+func (d Diagnostics) Count(severity Severity) int {
+	count := 0
+	for _, v := range d {
+		if v.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary - amount of diagnostics by severity, e.g. `2 unsupported, 5 dropped, 1 approximated`
+func (d Diagnostics) Summary() string {
+	var counts []string
+	for _, severity := range Severities {
+		counts = append(counts, fmt.Sprintf("%d %s", d.Count(severity), severity))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// Diagnoser - collects diagnostics of one object, nil diagnoser ignores them
+type Diagnoser struct {
+	diagnostics *Diagnostics
+	kind        string
+	id          string
+}
+
+// Dropped - field is ignored
+func (d *Diagnoser) Dropped(field string, format string, args ...interface{}) {
+	d.add(field, SeverityDropped, format, args...)
+}
+
+// Approximated - field is converted to the closest supported value
+func (d *Diagnoser) Approximated(field string, format string, args ...interface{}) {
+	d.add(field, SeverityApproximated, format, args...)
+}
+
+// Unsupported - value isn't supported and skipped
+func (d *Diagnoser) Unsupported(field string, format string, args ...interface{}) {
+	d.add(field, SeverityUnsupported, format, args...)
+}
+
+// Chart fields which some chart resources don't have, see UnsupportedChartFields
+const (
+	ChartTags            = "tags"
+	ChartGroupBy         = "options.groupBy"
+	ChartMinResolution   = "options.programOptions.minimumResolution"
+	ChartDisableSampling = "options.programOptions.disableSampling"
+	ChartTimezone        = "options.programOptions.timezone"
+)
+
+// UnsupportedChartFields - report fields which are set in the chart, but resource of the chart type doesn't have them
+func (d *Diagnoser) UnsupportedChartFields(chart *Chart, fields ...string) {
+	for _, field := range fields {
+		var name string // with verb for the message
+		var set bool
+		switch field {
+		case ChartTags:
+			name, set = "chart tags are", len(chart.Tags) > 0
+		case ChartGroupBy:
+			name, set = "group by is", len(chart.Options.GroupBy) > 0
+		case ChartMinResolution:
+			name = "minimum resolution is"
+			_, set = MinResolutionProc(chart)
+		case ChartDisableSampling:
+			name, set = "disabled sampling is", DisableSamplingProc(chart)
+		case ChartTimezone:
+			name, set = "timezone is", TimezoneProc(chart) != ""
+		}
+		if set {
+			d.Unsupported(field, "%s not supported by %s", name, Type[chart.Options.Type])
+		}
+	}
+}
+
+// add - add diagnostic for field of the object
+func (d *Diagnoser) add(field string, severity Severity, format string, args ...interface{}) {
+	if d == nil || d.diagnostics == nil {
+		return
+	}
+	d.diagnostics.Add(d.kind, d.id, field, severity, format, args...)
+}
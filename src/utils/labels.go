@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var labelRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Labeler - builds human-readable terraform resource labels from object names.
+// Label of the object is assigned once and reused for all references,
+// collisions get numeric suffix in order of assignment.
+// Labeler is safe for concurrent use, one labeler can be shared by converters.
+type Labeler struct {
+	mu     sync.Mutex
+	labels map[string]string // object id -> label
+	used   map[string]bool   // assigned labels
+}
+
+// NewLabeler - create labeler without known labels
+func NewLabeler() *Labeler {
+	return &Labeler{
+		labels: map[string]string{},
+		used:   map[string]bool{},
+	}
+}
+
+// LoadLabeler - create labeler with labels from name map file,
+// file contains JSON object with object id as key and label as value.
+// Missing file is not an error, it will be created by Save.
+func LoadLabeler(path string) (*Labeler, error) {
+	l := NewLabeler()
+	js, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(js, &l.labels); err != nil {
+		return nil, fmt.Errorf("Cannot load name map %s: %v", path, err)
+	}
+	for _, label := range l.labels {
+		l.used[label] = true
+	}
+	return l, nil
+}
+
+// Save - write all known labels to name map file
+func (l *Labeler) Save(path string) error {
+	l.mu.Lock()
+	js, err := json.MarshalIndent(l.labels, "", "  ")
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(js, '\n'), 0644)
+}
+
+// Label - terraform label for object with id and name
+func (l *Labeler) Label(id string, name string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if label, ok := l.labels[id]; ok {
+		return label
+	}
+
+	base := Slugify(name)
+	if base == "" {
+		base = Slugify(id)
+	}
+	// Labels must start with a letter or underscore
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "sfx_" + base
+	}
+
+	label := base
+	for i := 2; l.used[label]; i++ {
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	l.labels[id] = label
+	l.used[label] = true
+	return label
+}
+
+// Slugify - lower case name with non-alphanumeric characters replaced by underscore
+func Slugify(name string) string {
+	return strings.Trim(labelRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
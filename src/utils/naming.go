@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+// Kinds of generated resources
+const (
+	KindDashboardGroup = "dashboard_group"
+	KindDashboard      = "dashboard"
+	KindChart          = "chart"
+	KindDetector       = "detector"
+)
+
+// NamingPolicy - rules for names of generated resources
+type NamingPolicy struct {
+	Prefix   string
+	Suffix   string
+	Template *template.Template // has priority over Prefix and Suffix
+}
+
+// NameData - data available in naming template
+type NameData struct {
+	Kind string
+	ID   string
+	Name string
+}
+
+// NewNamingPolicy - create naming policy, tmpl is Go template with NameData fields,
+// e.g. `{{.Name}} ({{.Kind}})`
+func NewNamingPolicy(prefix string, suffix string, tmpl string) (*NamingPolicy, error) {
+	p := &NamingPolicy{Prefix: prefix, Suffix: suffix}
+	if tmpl == "" {
+		return p, nil
+	}
+
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	// Catch references to unknown fields before conversion
+	if err := t.Execute(ioutil.Discard, NameData{}); err != nil {
+		return nil, err
+	}
+	p.Template = t
+	return p, nil
+}
+
+// Name - apply policy to original name of the resource
+func (p *NamingPolicy) Name(kind string, id string, name string) string {
+	if p == nil {
+		return name
+	}
+	if p.Template != nil {
+		var buf bytes.Buffer
+		if err := p.Template.Execute(&buf, NameData{Kind: kind, ID: id, Name: name}); err != nil {
+			log.Printf("Cannot apply naming template to %s %s: %v", kind, id, err)
+			return name
+		}
+		return buf.String()
+	}
+	return p.Prefix + name + p.Suffix
+}
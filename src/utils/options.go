@@ -0,0 +1,33 @@
+package utils
+
+// Options - settings shared by all converters
+type Options struct {
+	Naming      *NamingPolicy
+	Labels      *Labeler
+	Diagnostics *Diagnostics
+	Variables   *Variables
+}
+
+// Label - terraform label of the generated resource
+func (o *Options) Label(id string, name string) string {
+	if o == nil || o.Labels == nil {
+		return LabelProc(id)
+	}
+	return o.Labels.Label(id, name)
+}
+
+// Name - name of the generated resource according to naming policy
+func (o *Options) Name(kind string, id string, name string) string {
+	if o == nil {
+		return name
+	}
+	return o.Naming.Name(kind, id, name)
+}
+
+// Diagnoser - diagnostics collector for object of kind with id
+func (o *Options) Diagnoser(kind string, id string) *Diagnoser {
+	if o == nil {
+		return nil
+	}
+	return &Diagnoser{diagnostics: o.Diagnostics, kind: kind, id: id}
+}
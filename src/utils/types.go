@@ -1,5 +1,10 @@
 package utils
 
+import (
+   "github.com/signalfx/signalfx-go/chart"
+   "github.com/signalfx/signalfx-go/detector"
+)
+
 // https://github.com/terraform-providers/terraform-provider-signalfx/blob/master/signalfx/resource_signalfx_time_chart.go
 
 var Color = map[int32]string{
@@ -154,6 +159,8 @@ var Type = map[string]string{
    "TimeSeriesChart": "signalfx_time_chart",
    "List":            "signalfx_list_chart",
    "Text":            "signalfx_text_chart",
+   "Event":           "signalfx_event_feed_chart",
+   "TableChart":      "signalfx_table_chart",
 }
 
 type testRulesV1 struct {
@@ -185,6 +192,41 @@ type RulesV1 struct {
    Severity            string
 }
 
+// Chart - chart with fields which are missing in signalfx-go model
+type Chart struct {
+   chart.Chart
+   Options *ChartOptions `json:"options,omitempty"`
+}
+
+// ChartOptions - chart options with fields which are missing in signalfx-go model
+type ChartOptions struct {
+   chart.Options
+   HideMissingValues bool                 `json:"hideMissingValues,omitempty"`
+   ProgramOptions    *ChartProgramOptions `json:"programOptions,omitempty"`
+}
+
+// ChartProgramOptions - chart program options with min delay
+type ChartProgramOptions struct {
+   chart.GeneralOptions
+   MinDelay *int64 `json:"minDelay,omitempty"`
+}
+
+// Detector - detector of current API with fields which are missing in signalfx-go model
+type Detector struct {
+   detector.Detector
+   AuthorizedWriters    *detector.AuthorizedWriters `json:"authorizedWriters,omitempty"`
+   LabelResolutions     map[string]int64            `json:"labelResolutions,omitempty"`
+   MinDelay             *int64                      `json:"minDelay,omitempty"`
+   Timezone             string                      `json:"timezone,omitempty"`
+   VisualizationOptions *DetectorVisualization      `json:"visualizationOptions,omitempty"`
+}
+
+// DetectorVisualization - detector visualization options with publish label options
+type DetectorVisualization struct {
+   detector.Visualization
+   PublishLabelOptions []*chart.PublishLabelOptions `json:"publishLabelOptions,omitempty"`
+}
+
 type DetectorV1 struct {
    Sf_description                     string
    Sf_createdOnMs                     int
@@ -220,15 +262,3 @@ var argparse struct {
    Force    bool     // Gets the value of --force
 }
 
-// DetectorRule structure
-type DetectorRule struct {
-   Description string `json:"description,omitempty"`
-   DetectLabel string `json:"detectLabel,omitempty"`
-   Disabled bool `json:"disabled,omitempty"`
-   ParameterizedBody string `json:"parameterizedBody,omitempty"`
-   ParameterizedSubject string `json:"parameterizedSubject,omitempty"`
-   RunbookUrl string   `json:"runbookUrl,omitempty"`
-   Severity   string `json:"severity,omitempty"`
-   Tip        string   `json:"tip,omitempty"`
-}
-
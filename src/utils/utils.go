@@ -3,17 +3,17 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/hashicorp/hcl2/hclwrite"
 	"github.com/signalfx/signalfx-go/chart"
 	"github.com/signalfx/signalfx-go/dashboard"
+	"github.com/signalfx/signalfx-go/dashboard_group"
 	"github.com/signalfx/signalfx-go/detector"
 	"github.com/signalfx/signalfx-go/notification"
-
-	"github.com/signalfx/signalfx-go"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -23,17 +23,85 @@ func LabelProc(name string) string {
 
 }
 
-// ProgramTextProc ...
-func ProgramTextProc(programText string) string {
-	return fmt.Sprintf("<<EOF\n%s\nEOF", programText)
+// HeredocProc - set attribute to the text, text is kept as is:
+// single line text is quoted string, multiline text is heredoc with escaped template sequences
+// and delimiter which doesn't collide with any line of the text.
+// Heredoc always ends with newline, so it's wrapped in chomp() if the text doesn't.
+func HeredocProc(body *hclwrite.Body, name string, text string) {
+	if !strings.Contains(text, "\n") {
+		body.SetAttributeValue(name, cty.StringVal(text))
+		return
+	}
+	chomp := !strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	delimiter := "EOF"
+	for i := 1; heredocCollides(lines, delimiter); i++ {
+		delimiter = fmt.Sprintf("EOF%d", i)
+	}
+
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte(name)},
+		{Type: hclsyntax.TokenEqual, Bytes: []byte("=")},
+	}
+	if chomp {
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("chomp")},
+			&hclwrite.Token{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		)
+	}
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<" + delimiter + "\n")})
+	for _, line := range lines {
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenStringLit, Bytes: []byte(EscapeTemplate(line) + "\n")})
+	}
+	tokens = append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(delimiter)},
+		&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	)
+	if chomp {
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")},
+			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		)
+	}
+	body.AppendUnstructuredTokens(tokens)
 }
 
-// MaxDelayProc ...
-func MaxDelayProc(chart *chart.Chart) int64 {
-	if chart.Options != nil || chart.Options.ProgramOptions != nil {
-		return 0
+// heredocCollides - true if any line closes heredoc with the delimiter
+func heredocCollides(lines []string, delimiter string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == delimiter {
+			return true
+		}
 	}
-	return int64(*chart.Options.ProgramOptions.MaxDelay / 1000) // Convert to sec
+	return false
+}
+
+// EscapeTemplate - escape terraform template sequences `${` and `%{`,
+// quoted strings set with SetAttributeValue are escaped by hclwrite
+func EscapeTemplate(text string) string {
+	text = strings.ReplaceAll(text, "${", "$${")
+	return strings.ReplaceAll(text, "%{", "%%{")
+}
+
+// MaxDelayProc - max delay in seconds, false if it's not set.
+// API uses ms and null or 0 for `Auto`, terraform uses seconds and omitted attribute for `Auto`.
+func MaxDelayProc(chart *Chart) (int64, bool) {
+	if chart.Options == nil || chart.Options.ProgramOptions == nil || chart.Options.ProgramOptions.MaxDelay == nil {
+		return 0, false
+	}
+	maxDelay := int64(*chart.Options.ProgramOptions.MaxDelay / 1000) // Convert to sec
+	return maxDelay, maxDelay > 0
+}
+
+// MinDelayProc - min delay in seconds, false if it's not set, units and `Auto` are the same as for max delay.
+// signalfx-go model doesn't have it, it's decoded from raw JSON.
+func MinDelayProc(chart *Chart) (int64, bool) {
+	if chart.Options == nil || chart.Options.ProgramOptions == nil || chart.Options.ProgramOptions.MinDelay == nil {
+		return 0, false
+	}
+	minDelay := *chart.Options.ProgramOptions.MinDelay / 1000 // Convert to sec
+	return minDelay, minDelay > 0
 }
 
 // MaxDelayDetectorProc ...
@@ -59,19 +127,53 @@ func ListOfTeamsDetectorProc(detector *detector.Detector) []cty.Value {
 }
 
 // RefreshIntervalProc ...
-func RefreshIntervalProc(chart *chart.Chart) int64 {
+func RefreshIntervalProc(chart *Chart) int64 {
 	if chart.Options.RefreshInterval == nil {
 		return 0
 	}
 	return int64(*chart.Options.RefreshInterval / 1000) // Convert to sec
 }
 
-// MinResolutionProc ...
-func MinResolutionProc(chart *chart.Chart) int64 {
-	if chart.Options != nil || chart.Options.ProgramOptions != nil {
-		return 0
+// MinResolutionProc - minimum resolution in seconds, false if it's not set (`Auto`)
+func MinResolutionProc(chart *Chart) (int64, bool) {
+	if chart.Options == nil || chart.Options.ProgramOptions == nil || chart.Options.ProgramOptions.MinimumResolution == nil {
+		return 0, false
+	}
+	minResolution := int64(*chart.Options.ProgramOptions.MinimumResolution / 1000) // Convert to sec
+	return minResolution, minResolution > 0
+}
+
+// SortByProc - sort property with direction prefix, `+` for ascending and `-` for descending.
+// Property is taken from sortBy or sortProperty, direction from prefix of sortBy or sortDirection,
+// property without direction is sorted ascending.
+func SortByProc(chart *Chart, d *Diagnoser) string {
+	property := chart.Options.SortBy
+	if property == "" {
+		property = chart.Options.SortProperty
+	}
+	if property == "" || strings.HasPrefix(property, "+") || strings.HasPrefix(property, "-") {
+		return property
+	}
+
+	switch chart.Options.SortDirection {
+	case "Ascending":
+		return "+" + property
+	case "Descending":
+		return "-" + property
+	case "":
+		d.Approximated("options.sortDirection", "sort direction of %s is not set, ascending is used", property)
+	default:
+		d.Approximated("options.sortDirection", "unknown sort direction %s of %s, ascending is used", chart.Options.SortDirection, property)
+	}
+	return "+" + property
+}
+
+// TimezoneProc ...
+func TimezoneProc(chart *Chart) string {
+	if chart.Options == nil || chart.Options.ProgramOptions == nil {
+		return ""
 	}
-	return int64(*chart.Options.ProgramOptions.MinimumResolution / 1000) // Convert to sec
+	return chart.Options.ProgramOptions.Timezone
 }
 
 // StrToInt ...
@@ -84,7 +186,7 @@ func StrToInt(s string) int64 {
 }
 
 // GroupByProc ...
-func GroupByProc(chart *chart.Chart) cty.Value {
+func GroupByProc(chart *Chart) cty.Value {
 	groupBy := chart.Options.GroupBy
 	groupByList := []cty.Value{}
 
@@ -98,16 +200,36 @@ func GroupByProc(chart *chart.Chart) cty.Value {
 	return cty.ListVal(groupByList)
 }
 
-// DisableSamplingProc ...
-func DisableSamplingProc(chart *chart.Chart) cty.Value {
-	if chart.Options != nil && chart.Options.ProgramOptions != nil {
-		return cty.BoolVal(chart.Options.ProgramOptions.DisableSampling)
+// DisableSamplingProc - true if sampling is disabled, enabled sampling is terraform default
+func DisableSamplingProc(chart *Chart) bool {
+	return chart.Options != nil && chart.Options.ProgramOptions != nil && chart.Options.ProgramOptions.DisableSampling
+}
+
+// TimeRangeProc - write time range of type in seconds, API uses ms,
+// see https://github.com/terraform-providers/terraform-provider-signalfx/issues/55.
+// Relative range without value is the default range and omitted,
+// absolute range is written with the bounds which are set.
+func TimeRangeProc(body *hclwrite.Body, field string, timeType string, timeRange, start, end *int64, d *Diagnoser) {
+	switch timeType {
+	case "":
+	case "relative":
+		if timeRange != nil {
+			body.SetAttributeValue("time_range", cty.NumberIntVal(*timeRange/1000))
+		}
+	case "absolute":
+		if start != nil {
+			body.SetAttributeValue("start_time", cty.NumberIntVal(*start/1000))
+		}
+		if end != nil {
+			body.SetAttributeValue("end_time", cty.NumberIntVal(*end/1000))
+		}
+	default:
+		d.Unsupported(field+".type", "unknown time type %q", timeType)
 	}
-	return cty.BoolVal(false)
 }
 
 // ColorRangeProc ...
-func ColorRangeProc(c *chart.Chart, cb *hclwrite.Body) {
+func ColorRangeProc(c *Chart, cb *hclwrite.Body, d *Diagnoser) error {
 	colorRange := c.Options.ColorRange
 	cr := ColorRangeOptions{}
 	cr.Color = colorRange.Color
@@ -117,11 +239,12 @@ func ColorRangeProc(c *chart.Chart, cb *hclwrite.Body) {
 	// default color range settings, empty structure is not allowed
 	if colorRange.Color == "" {
 		cr.Color = "#05ce00" // Green scale pattern
+		d.Approximated("options.colorRange.color", "color is not set, %s is used", cr.Color)
 	}
 	
 	js, err := json.Marshal(cr)
 	if err != nil {
-		log.Fatal("Cannot marshal SecondaryVisualization{} structure", err)
+		return fmt.Errorf("Cannot marshal ColorRangeOptions{} structure: %v", err)
 	}
 	// map json strings to an interface
 	// convert from []byte to strings and value type
@@ -129,10 +252,11 @@ func ColorRangeProc(c *chart.Chart, cb *hclwrite.Body) {
 	json.Unmarshal(js, &s)
 
 	setAttributeOptions(cb, s, "color_range", c.Options.Type)
+	return nil
 }
 
 // ColorScale2Proc - create color_scale body
-func ColorScale2Proc(c *chart.Chart, cb *hclwrite.Body) {
+func ColorScale2Proc(c *Chart, cb *hclwrite.Body) error {
 	for _, f := range c.Options.ColorScale2 {
 		sv := SecondaryVisualization{}
 		sv.Gt = f.Gt
@@ -143,7 +267,7 @@ func ColorScale2Proc(c *chart.Chart, cb *hclwrite.Body) {
 
 		js, err := json.Marshal(sv)
 		if err != nil {
-			log.Fatal("Cannot marshal SecondaryVisualization{} structure", err)
+			return fmt.Errorf("Cannot marshal SecondaryVisualization{} structure: %v", err)
 		}
 
 		// map json strings to an interface
@@ -153,6 +277,7 @@ func ColorScale2Proc(c *chart.Chart, cb *hclwrite.Body) {
 
 		setAttributeOptions(cb, s, "color_scale", c.Options.Type)
 	}
+	return nil
 }
 
 // FilterValueProc ...
@@ -174,7 +299,10 @@ func VariableValueProc(filter *dashboard.ChartsWebUiFilter) cty.Value {
 }
 
 // DensityProc ...
-func DensityProc(density *dashboard.DashboardChartDensity) cty.Value {
+func DensityProc(density *dashboard.DashboardChartDensity, d *Diagnoser) cty.Value {
+	if density == nil {
+		return cty.StringVal("default")
+	}
 
 	switch *density {
 	case dashboard.DEFAULT:
@@ -190,6 +318,7 @@ func DensityProc(density *dashboard.DashboardChartDensity) cty.Value {
 		return cty.StringVal("low")
 	}
 
+	d.Approximated("chartDensity", "unknown density %s, default is used", *density)
 	return cty.StringVal("default")
 }
 
@@ -199,109 +328,104 @@ Adopted from:
 https://github.com/terraform-providers/terraform-provider-signalfx/blob/master/signalfx/notifications.go
 Thanks, Cory
 */
-func NotificationProc(rule detector.Rule) cty.Value {
+func NotificationProc(rule detector.Rule, d *Diagnoser, v *Variables, owner string) []Template {
 	notifications := rule.Notifications
-	var notificationList []cty.Value
-	if len(notifications) == 0 {
-		return cty.ListValEmpty(cty.String)
-	}
+	var notificationList []Template
 
 	for _, n := range notifications {
-		route := ""
+		var route Template
 		nt := n.Type
 		switch nt {
 		case "BigPanda":
 			bp := n.Value.(*notification.BigPandaNotification)
-			route = fmt.Sprintf("%s,%s", nt, bp.CredentialId)
+			route = Template{Field{Value: nt}, v.Credential(nt, bp.CredentialId)}
 		case "Email":
 			em := n.Value.(*notification.EmailNotification)
-			route = fmt.Sprintf("%s,%s", nt, em.Email)
+			route = Literals(nt, em.Email)
 		case "Office365":
 			off := n.Value.(*notification.Office365Notification)
-			route = fmt.Sprintf("%s,%s", nt, off.CredentialId)
+			route = Template{Field{Value: nt}, v.Credential(nt, off.CredentialId)}
 		case "Opsgenie":
 			og := n.Value.(*notification.OpsgenieNotification)
-			route = fmt.Sprintf("%s,%s,%s,%s,%s", nt, og.CredentialId, og.ResponderName, og.ResponderId, og.ResponderType)
+			route = append(Template{Field{Value: nt}, v.Credential(nt, og.CredentialId)}, Literals(og.ResponderName, og.ResponderId, og.ResponderType)...)
 		case "PagerDuty":
 			pd := n.Value.(*notification.PagerDutyNotification)
-			route = fmt.Sprintf("%s,%s", nt, pd.CredentialId)
+			route = Template{Field{Value: nt}, v.Credential(nt, pd.CredentialId)}
 		case "ServiceNow":
 			sn := n.Value.(*notification.ServiceNowNotification)
-			route = fmt.Sprintf("%s,%s", nt, sn.CredentialId)
+			route = Template{Field{Value: nt}, v.Credential(nt, sn.CredentialId)}
 		case "Slack":
 			sl := n.Value.(*notification.SlackNotification)
-			route = fmt.Sprintf("%s,%s,%s", nt, sl.CredentialId, sl.Channel)
+			route = Template{Field{Value: nt}, v.Credential(nt, sl.CredentialId), Field{Value: sl.Channel}}
 		case "Team":
 			t := n.Value.(*notification.TeamNotification)
-			route = fmt.Sprintf("%s,%s", nt, t.Team)
+			route = Literals(nt, t.Team)
 		case "TeamEmail":
 			te := n.Value.(*notification.TeamEmailNotification)
-			route = fmt.Sprintf("%s,%s", nt, te.Team)
+			route = Literals(nt, te.Team)
 		case "VictorOps":
 			vo := n.Value.(*notification.VictorOpsNotification)
-			route = fmt.Sprintf("%s,%s,%s", nt, vo.CredentialId, vo.RoutingKey)
+			route = Template{Field{Value: nt}, v.Credential(nt, vo.CredentialId), Field{Value: vo.RoutingKey}}
 		case "Webhook":
 			wh := n.Value.(*notification.WebhookNotification)
-			route = fmt.Sprintf("%s,%s,%s,%s", nt, wh.CredentialId, wh.Secret, wh.Url)
+			secret := v.Secret(owner+"_webhook_secret", fmt.Sprintf("Secret of webhook %s in detector %s", wh.Url, owner), wh.Secret)
+			route = Template{Field{Value: nt}, v.Credential(nt, wh.CredentialId), secret, Field{Value: wh.Url}}
 		case "XMatters":
 			xm := n.Value.(*notification.XMattersNotification)
-			route = fmt.Sprintf("%s,%s", nt, xm.CredentialId)
+			route = Template{Field{Value: nt}, v.Credential(nt, xm.CredentialId)}
+		default:
+			d.Unsupported("rules.notifications", "notification type %s of rule %s is skipped", nt, rule.DetectLabel)
+			continue
 		}
 
-		ctyV := cty.StringVal(route)
-
-		notificationList = append(notificationList, ctyV)
+		notificationList = append(notificationList, route)
 	}
-	return cty.TupleVal(notificationList)
+	return notificationList
 }
 
-// NotificationProcV1 ...
-func NotificationProcV1(notifications []map[string]string) cty.Value {
-	var notifocationList []cty.Value
-	if len(notifications) == 0 {
-		return cty.ListValEmpty(cty.String)
-	}
+// NotificationProcV1 - notifications of old version API converted to current format
+func NotificationProcV1(notifications []map[string]string, d *Diagnoser, v *Variables, owner string) []Template {
+	var notificationList []Template
 	for _, item := range notifications {
-		route := "" // Create new string with notification routing
-		switch item["type"] {
+		var route Template // Create new string with notification routing
+		switch strings.ToLower(item["type"]) {
 		case "email":
-			route = fmt.Sprintf("Email,%s", item["email"])
+			route = Literals("Email", item["email"])
 		case "pagerduty":
-			route = fmt.Sprintf("PagerDuty,%s", item["credentialId"])
+			route = Template{Field{Value: "PagerDuty"}, v.Credential("PagerDuty", item["credentialId"])}
 		case "bigpanda":
-			route = fmt.Sprintf("BigPanda,%s", item["credentialId"])
+			route = Template{Field{Value: "BigPanda"}, v.Credential("BigPanda", item["credentialId"])}
 		case "office365":
-			route = fmt.Sprintf("Office365,%s", item["credentialId"])
+			route = Template{Field{Value: "Office365"}, v.Credential("Office365", item["credentialId"])}
 		case "servicenow":
-			route = fmt.Sprintf("ServiceNow,%s", item["credentialId"])
+			route = Template{Field{Value: "ServiceNow"}, v.Credential("ServiceNow", item["credentialId"])}
 		case "xmatters":
-			route = fmt.Sprintf("XMatters,%s", item["credentialId"])
+			route = Template{Field{Value: "XMatters"}, v.Credential("XMatters", item["credentialId"])}
 		case "slack":
-			route = fmt.Sprintf("Slack,%s,%s", item["credentialId"], item["channel"])
+			route = Template{Field{Value: "Slack"}, v.Credential("Slack", item["credentialId"]), Field{Value: item["channel"]}}
 		case "webhook":
-			route = fmt.Sprintf("WebHook%s,%s", item["secret"], item["url"])
+			secret := v.Secret(owner+"_webhook_secret", fmt.Sprintf("Secret of webhook %s in detector %s", item["url"], owner), item["secret"])
+			route = Template{Field{Value: "Webhook"}, v.Credential("Webhook", item["credentialId"]), secret, Field{Value: item["url"]}}
 		case "team":
-			route = fmt.Sprintf("Team,%s", item["team"])
+			route = Literals("Team", item["team"])
 		case "teamemail":
-			route = fmt.Sprintf("TeamEmail,%s", item["team"])
+			route = Literals("TeamEmail", item["team"])
 		case "opsgenie":
-			route = fmt.Sprintf("OpsGenie,%s,%s,%s,%s,%s",
-				item["credentialId"],
-				item["credentialName"],
-				item["responderName"],
-				item["responderId"],
-				item["responderType"])
+			route = append(Template{Field{Value: "Opsgenie"}, v.Credential("Opsgenie", item["credentialId"])},
+				Literals(item["responderName"], item["responderId"], item["responderType"])...)
 		case "victorops":
-			route = fmt.Sprintf("VictorOps,%s,%s", item["credentialId"], item["routingKey"])
+			route = Template{Field{Value: "VictorOps"}, v.Credential("VictorOps", item["credentialId"]), Field{Value: item["routingKey"]}}
+		default:
+			d.Unsupported("sf_rules.notifications", "notification type %s is skipped", item["type"])
+			continue
 		}
-		ctyV := cty.StringVal(route)
-		notifocationList = append(notifocationList, ctyV)
+		notificationList = append(notificationList, route)
 	}
-	return cty.TupleVal(notifocationList)
+	return notificationList
 }
 
-// OnChartLegendProc ...
-func OnChartLegendProc(chart *chart.Chart) cty.Value {
+// OnChartLegendProc - dimension shown in the chart legend, empty if legend isn't shown on the chart
+func OnChartLegendProc(chart *Chart) string {
 
 	if chart.Options.OnChartLegendOptions != nil {
 		dimensionInLegend := chart.Options.OnChartLegendOptions.DimensionInLegend
@@ -322,13 +446,13 @@ func OnChartLegendProc(chart *chart.Chart) cty.Value {
 			dimensionInLegend = "metric"
 		}
 
-		return cty.StringVal(dimensionInLegend)
+		return dimensionInLegend
 	}
-	return cty.StringVal("")
+	return ""
 }
 
 // LegendShowProc ...
-func LegendShowProc(chart *chart.Chart) cty.Value {
+func LegendShowProc(chart *Chart) cty.Value {
 	var valueList []cty.Value
 	if len(chart.Options.LegendOptions.Fields) == 0 {
 		return cty.ListValEmpty(cty.String)
@@ -345,7 +469,7 @@ func LegendShowProc(chart *chart.Chart) cty.Value {
 }
 
 // EventProc - create event_options body
-func EventProc(c *chart.Chart, cb *hclwrite.Body) {
+func EventProc(c *Chart, cb *hclwrite.Body) error {
 	// assign all options available
 	// if no options it wont panic, structure uses `omitempty`
 	if c.Options.EventPublishLabelOptions != nil {
@@ -358,7 +482,7 @@ func EventProc(c *chart.Chart, cb *hclwrite.Body) {
 			// marshal structure to json strings
 			js, err := json.Marshal(e)
 			if err != nil {
-				log.Fatal("Cannot marshal EventPublishLabelOptions{} structure", err)
+				return fmt.Errorf("Cannot marshal EventPublishLabelOptions{} structure: %v", err)
 			}
 
 			// map json strings to an interface
@@ -369,52 +493,141 @@ func EventProc(c *chart.Chart, cb *hclwrite.Body) {
 			setAttributeOptions(cb, s, "event_options", c.Options.Type)
 		}
 	}
+	return nil
 }
 
-// ShortVizProc ...
-func ShortVizProc(chart *chart.Chart) cty.Value {
-	var valueList []cty.Value
-	if len(chart.Options.PublishLabelOptions) == 0 {
-		return cty.ListValEmpty(cty.String)
-	}
-	for _, v := range chart.Options.PublishLabelOptions {
-		attr := map[string]cty.Value{}
-		attr["display_name"] = cty.StringVal(v.DisplayName)
-		attr["label"] = cty.StringVal(v.Label)
-		// FIXME: Ugly hack here, 0 not null!
-		if v.PaletteIndex != nil {
-			attr["color"] = cty.StringVal(Color[*v.PaletteIndex])
+// ShortVizProc - create viz_options blocks with label, color, display name and value options,
+// resources without plot type and axis in viz_options (detector, table chart) use it, field is API field of options
+func ShortVizProc(cb *hclwrite.Body, options []*chart.PublishLabelOptions, field string, d *Diagnoser) {
+	for _, p := range options {
+		vizBody := cb.AppendNewBlock("viz_options", nil).Body()
+		vizBody.SetAttributeValue("label", cty.StringVal(p.Label))
+		if p.PaletteIndex != nil {
+			color, ok := OptionsColor[*p.PaletteIndex]
+			if ok {
+				vizBody.SetAttributeValue("color", cty.StringVal(color))
+			} else {
+				d.Dropped(field+".paletteIndex", "unknown color %d of %s", *p.PaletteIndex, p.Label)
+			}
+		}
+		if p.DisplayName != "" {
+			vizBody.SetAttributeValue("display_name", cty.StringVal(p.DisplayName))
 		}
-		if v.ValueUnit != "" { // Empty string not allowed
-			attr["value_unit"] = cty.StringVal(v.ValueUnit)
+		if p.ValueUnit != "" {
+			vizBody.SetAttributeValue("value_unit", cty.StringVal(p.ValueUnit))
 		}
-		attr["value_prefix"] = cty.StringVal(v.ValuePrefix)
-		attr["value_suffix"] = cty.StringVal(v.ValueSuffix)
+		if p.ValuePrefix != "" {
+			vizBody.SetAttributeValue("value_prefix", cty.StringVal(p.ValuePrefix))
+		}
+		if p.ValueSuffix != "" {
+			vizBody.SetAttributeValue("value_suffix", cty.StringVal(p.ValueSuffix))
+		}
+	}
+}
 
-		ctyV := cty.ObjectVal(attr)
-		valueList = append(valueList, ctyV)
+// StringListProc ...
+func StringListProc(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	var valueList []cty.Value
+	for _, v := range values {
+		valueList = append(valueList, cty.StringVal(v))
+	}
+	return cty.ListVal(valueList)
+}
 
+// CreateDashboardGroup - function for generating dashboard group
+func CreateDashboardGroup(f *hclwrite.File, group *dashboard_group.DashboardGroup, opts *Options) (*hclwrite.Body, error) {
+	rootBody := f.Body()
+	groupBlock := rootBody.AppendNewBlock("resource", []string{"signalfx_dashboard_group", opts.Label(group.Id, group.Name)})
+	groupBody := groupBlock.Body()
+	groupBody.SetAttributeValue("name", cty.StringVal(opts.Name(KindDashboardGroup, group.Id, group.Name)))
+	groupBody.SetAttributeValue("description", cty.StringVal(group.Description))
+	if len(group.Teams) > 0 {
+		groupBody.SetAttributeValue("teams", StringListProc(group.Teams))
 	}
-	return cty.TupleVal(valueList)
+	if len(group.AuthorizedWriters.Teams) > 0 {
+		groupBody.SetAttributeValue("authorized_writer_teams", StringListProc(group.AuthorizedWriters.Teams))
+	}
+	if len(group.AuthorizedWriters.Users) > 0 {
+		groupBody.SetAttributeValue("authorized_writer_users", StringListProc(group.AuthorizedWriters.Users))
+	}
+	if len(group.DashboardConfigs) > 0 {
+		opts.Diagnoser(KindDashboardGroup, group.Id).Dropped("dashboardConfigs", "dashboard mirrors and their filter overrides are not converted")
+	}
+	groupBody.AppendNewline()
+	return groupBody, nil
 }
 
-// CreateDashboard - function for generating dashboard
-func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, client *signalfx.Client) *hclwrite.Body {
+// dashboardFilters - filters of the dashboard, empty if JSON of dashboard has no filters
+func dashboardFilters(d *dashboard.Dashboard) *dashboard.ChartsFilters {
+	if d.Filters == nil {
+		return &dashboard.ChartsFilters{}
+	}
+	return d.Filters
+}
+
+// CheckChart - error if chart misses id or options which are required by converters,
+// JSON of other object is decoded to such chart
+func CheckChart(chart *Chart) error {
+	if chart.Id == "" || chart.Options == nil {
+		return fmt.Errorf("object is not a chart, it has no id or options")
+	}
+	return nil
+}
+
+// CreateDashboard - function for generating dashboard,
+// charts contains all charts of the dashboard by id.
+// If group is not empty, dashboard_group refers to the
+// signalfx_dashboard_group resource with that label.
+func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, charts map[string]*Chart, group string, opts *Options) (*hclwrite.Body, error) {
+	// Check charts before anything is written to the file
+	for _, chart := range dashboard.Charts {
+		if _, ok := charts[chart.ChartId]; !ok {
+			return nil, fmt.Errorf("Chart %s of dashboard %s not found", chart.ChartId, dashboard.Id)
+		}
+	}
+
+	d := opts.Diagnoser(KindDashboard, dashboard.Id)
+
 	rootBody := f.Body()
-	dashBlock := rootBody.AppendNewBlock("resource", []string{"signalfx_dashboard", dashboard.Id})
+	dashBlock := rootBody.AppendNewBlock("resource", []string{"signalfx_dashboard", opts.Label(dashboard.Id, dashboard.Name)})
 	dashBody := dashBlock.Body()
-	dashBody.SetAttributeValue("dashboard_group", cty.StringVal(dashboard.GroupId))
-	dashBody.SetAttributeValue("name", cty.StringVal(fmt.Sprintf("test-%s", dashboard.Name))) // TODO: Hardcode to prevent self-destroy
+	if group != "" {
+		dashBody.SetAttributeTraversal("dashboard_group", hcl.Traversal{
+			hcl.TraverseRoot{Name: "signalfx_dashboard_group"},
+			hcl.TraverseAttr{Name: group},
+			hcl.TraverseAttr{Name: "id"},
+		})
+	} else {
+		dashBody.SetAttributeValue("dashboard_group", cty.StringVal(dashboard.GroupId))
+	}
+	dashBody.SetAttributeValue("name", cty.StringVal(opts.Name(KindDashboard, dashboard.Id, dashboard.Name)))
 	dashBody.SetAttributeValue("description", cty.StringVal(dashboard.Description))
-	dashBody.SetAttributeValue("charts_resolution", DensityProc(dashboard.ChartDensity))
+	dashBody.SetAttributeValue("charts_resolution", DensityProc(dashboard.ChartDensity, d))
+	if len(dashboard.Tags) > 0 {
+		d.Dropped("tags", "dashboard tags are not converted")
+	}
+	if dashboard.AuthorizedWriters != nil && (len(dashboard.AuthorizedWriters.Teams) > 0 || len(dashboard.AuthorizedWriters.Users) > 0) {
+		d.Dropped("authorizedWriters", "dashboard permissions are not converted")
+	}
+	if dashboard.MaxDelayOverride != nil {
+		d.Dropped("maxDelayOverride", "max delay override is not converted")
+	}
+	if len(dashboard.EventOverlays) > 0 || len(dashboard.SelectedEventOverlays) > 0 {
+		d.Dropped("eventOverlays", "event overlays are not converted")
+	}
+	filters := dashboardFilters(dashboard)
+
 	// Complex `Time` logic here.
 	// Terraform provider has different description,
 	// SignalFX API has different fields,
 	// Variables from documentation doesn't implemented.
 	// Meh
-	if dashboard.Filters.Time != nil { // If fields don't exist - SignalFX will use system vars.
-		start := string(dashboard.Filters.Time.Start)
-		end := string(dashboard.Filters.Time.End)
+	if filters.Time != nil { // If fields don't exist - SignalFX will use system vars.
+		start := string(filters.Time.Start)
+		end := string(filters.Time.End)
 		if end == "Now" { // If `Now` - scale relative.
 			dashBody.SetAttributeValue("time_range", cty.StringVal(start))
 		} else { // If not - absolute
@@ -426,7 +639,7 @@ func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, client *s
 	dashBody.AppendNewline()
 
 	// Filter section processing
-	for _, filter := range dashboard.Filters.Sources {
+	for _, filter := range filters.Sources {
 		filterBlock := dashBody.AppendNewBlock("filter", nil)
 		filterBody := filterBlock.Body()
 		filterBody.SetAttributeValue("property", cty.StringVal(filter.Property))
@@ -437,7 +650,7 @@ func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, client *s
 	}
 
 	// Variables section processing
-	for _, variable := range dashboard.Filters.Variables {
+	for _, variable := range filters.Variables {
 		// Not full implementation,
 		// see https://github.com/signalfx/terraform-provider-signalfx/blob/master/website/docs/r/dashboard.html.markdown
 
@@ -456,15 +669,14 @@ func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, client *s
 
 	// Charts position processing
 	for _, chart := range dashboard.Charts {
-		// Receive data about chart from API
-		// TODO: Need to implement init() section and Client class
-		chartHelper, err := client.GetChart(chart.ChartId)
-		if err != nil {
-			log.Printf("Chart error: %v", err)
-			log.Fatal("Can't get chart")
+		chartHelper := charts[chart.ChartId]
+
+		// Unsupported chart types are not generated, reference would be dangling
+		if _, ok := Type[chartHelper.Options.Type]; !ok {
+			continue
 		}
 
-		chartID := fmt.Sprintf("%s.%s.id", Type[chartHelper.Options.Type], LabelProc(chartHelper.Id))
+		chartID := fmt.Sprintf("%s.%s.id", Type[chartHelper.Options.Type], opts.Label(chartHelper.Id, chartHelper.Name))
 
 		chartPosBlock := dashBody.AppendNewBlock("chart", nil)
 		chartPosBody := chartPosBlock.Body()
@@ -476,40 +688,39 @@ func CreateDashboard(f *hclwrite.File, dashboard *dashboard.Dashboard, client *s
 		chartPosBody.SetAttributeValue("height", cty.NumberIntVal(int64(chart.Height)))
 	}
 	dashBody.AppendNewline()
-	return dashBody
+	return dashBody, nil
 }
 
 // GetVizOptions - create viz_options body
-func GetVizOptions(c *chart.Chart, cb *hclwrite.Body) {
+func GetVizOptions(c *Chart, cb *hclwrite.Body) error {
 
 	// assign all options available
 	// if no options it wont panic, structure uses `omitempty`
-	if c.Options.LegendOptions != nil {
-		for _, f := range c.Options.PublishLabelOptions {
-			p := PublishLabelOptions{}
-			p.Label = f.Label
-			p.DisplayName = f.DisplayName
-			p.PaletteIndex = f.PaletteIndex
-			p.YAxis = f.YAxis
-			p.PlotType = f.PlotType
-			p.ValueUnit = f.ValueUnit
-			p.ValuePrefix = f.ValuePrefix
-			p.ValueSuffix = f.ValueSuffix
-
-			// marshal structure to json strings
-			js, err := json.Marshal(p)
-			if err != nil {
-				log.Fatal("Cannot marshal PublishLabelOptions{} structure", err)
-			}
+	for _, f := range c.Options.PublishLabelOptions {
+		p := PublishLabelOptions{}
+		p.Label = f.Label
+		p.DisplayName = f.DisplayName
+		p.PaletteIndex = f.PaletteIndex
+		p.YAxis = f.YAxis
+		p.PlotType = f.PlotType
+		p.ValueUnit = f.ValueUnit
+		p.ValuePrefix = f.ValuePrefix
+		p.ValueSuffix = f.ValueSuffix
+
+		// marshal structure to json strings
+		js, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("Cannot marshal PublishLabelOptions{} structure: %v", err)
+		}
 
-			// map json strings to an interface
-			// convert from []byte to strings and value type
-			s := make(map[string]interface{})
-			json.Unmarshal(js, &s)
+		// map json strings to an interface
+		// convert from []byte to strings and value type
+		s := make(map[string]interface{})
+		json.Unmarshal(js, &s)
 
-			setAttributeOptions(cb, s, "viz_options", f.PlotType)
-		}
+		setAttributeOptions(cb, s, "viz_options", f.PlotType)
 	}
+	return nil
 }
 
 // setAttributeOptions - fill Chart Body with attributes
@@ -550,15 +761,53 @@ func setAttributeOptions(cb *hclwrite.Body, s map[string]interface{}, name strin
 	}
 }
 
-// GetLegendOptionsBlock - create legend_options_fields body
-func GetLegendOptionsBlock(c *chart.Chart, cb *hclwrite.Body) {
-	legend := &hclwrite.Block{}
+// AxisProc - create axis block with name, axis without values is omitted
+func AxisProc(cb *hclwrite.Body, name string, axis *chart.Axes) {
+	if axis == nil || *axis == (chart.Axes{}) {
+		return
+	}
+	axisBody := cb.AppendNewBlock(name, nil).Body()
+	if axis.Label != "" {
+		axisBody.SetAttributeValue("label", cty.StringVal(axis.Label))
+	}
+	if axis.Min != nil {
+		axisBody.SetAttributeValue("min_value", Float32Val(*axis.Min))
+	}
+	if axis.Max != nil {
+		axisBody.SetAttributeValue("max_value", Float32Val(*axis.Max))
+	}
+	if axis.HighWatermark != nil {
+		axisBody.SetAttributeValue("high_watermark", Float32Val(*axis.HighWatermark))
+	}
+	if axis.HighWatermarkLabel != "" {
+		axisBody.SetAttributeValue("high_watermark_label", cty.StringVal(axis.HighWatermarkLabel))
+	}
+	if axis.LowWatermark != nil {
+		axisBody.SetAttributeValue("low_watermark", Float32Val(*axis.LowWatermark))
+	}
+	if axis.LowWatermarkLabel != "" {
+		axisBody.SetAttributeValue("low_watermark_label", cty.StringVal(axis.LowWatermarkLabel))
+	}
+}
+
+// Float32Val - number value of float32 without float64 conversion noise, e.g. 0.1 instead of 0.10000000149
+func Float32Val(v float32) cty.Value {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+	return cty.NumberFloatVal(f)
+}
+
+// GetLegendOptionsBlock - create legend_options_fields body.
+// Hidden fields are disabled here, provider doesn't allow legend_fields_to_hide together with legend_options_fields,
+// every hidden field is reported as approximated
+func GetLegendOptionsBlock(c *Chart, cb *hclwrite.Body, d *Diagnoser) {
 	if c.Options.LegendOptions != nil {
 		for _, field := range c.Options.LegendOptions.Fields {
-			legend = cb.AppendNewBlock("legend_options_fields", nil)
-			legendOptionsBody := legend.Body()
+			legendOptionsBody := cb.AppendNewBlock("legend_options_fields", nil).Body()
 			legendOptionsBody.SetAttributeValue("property", cty.StringVal(field.Property))
 			legendOptionsBody.SetAttributeValue("enabled", cty.BoolVal(field.Enabled))
+			if !field.Enabled {
+				d.Approximated("options.legendOptions.fields", "hidden legend field %s is disabled in legend_options_fields instead of legend_fields_to_hide", field.Property)
+			}
 		}
 	}
 }
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// testChart - chart decoded from JSON of API response
+func testChart(t *testing.T, js string) *Chart {
+	t.Helper()
+	c := &Chart{}
+	if err := json.Unmarshal([]byte(js), c); err != nil {
+		t.Fatalf("Can't decode chart: %v", err)
+	}
+	return c
+}
+
+func TestProgramOptionsProc(t *testing.T) {
+	tests := []struct {
+		name            string
+		chart           string
+		maxDelay        int64
+		maxDelaySet     bool
+		minDelay        int64
+		minDelaySet     bool
+		minResolution   int64
+		minResolutionOk bool
+		disableSampling bool
+		timezone        string
+	}{
+		{
+			name:  "no options",
+			chart: `{"id": "A"}`,
+		},
+		{
+			name:  "no program options",
+			chart: `{"id": "A", "options": {"type": "TimeSeriesChart"}}`,
+		},
+		{
+			name:  "null values",
+			chart: `{"id": "A", "options": {"programOptions": {"maxDelay": null, "minDelay": null, "minimumResolution": null, "timezone": null}}}`,
+		},
+		{
+			name:  "zero values are auto",
+			chart: `{"id": "A", "options": {"programOptions": {"maxDelay": 0, "minDelay": 0, "minimumResolution": 0, "disableSampling": false, "timezone": ""}}}`,
+		},
+		{
+			name:            "set values",
+			chart:           `{"id": "A", "options": {"programOptions": {"maxDelay": 15000, "minDelay": 5000, "minimumResolution": 60000, "disableSampling": true, "timezone": "Europe/Berlin"}}}`,
+			maxDelay:        15,
+			maxDelaySet:     true,
+			minDelay:        5,
+			minDelaySet:     true,
+			minResolution:   60,
+			minResolutionOk: true,
+			disableSampling: true,
+			timezone:        "Europe/Berlin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chart := testChart(t, tt.chart)
+			if v, ok := MaxDelayProc(chart); v != tt.maxDelay || ok != tt.maxDelaySet {
+				t.Errorf("MaxDelayProc() = %d, %v, want %d, %v", v, ok, tt.maxDelay, tt.maxDelaySet)
+			}
+			if v, ok := MinDelayProc(chart); v != tt.minDelay || ok != tt.minDelaySet {
+				t.Errorf("MinDelayProc() = %d, %v, want %d, %v", v, ok, tt.minDelay, tt.minDelaySet)
+			}
+			if v, ok := MinResolutionProc(chart); v != tt.minResolution || ok != tt.minResolutionOk {
+				t.Errorf("MinResolutionProc() = %d, %v, want %d, %v", v, ok, tt.minResolution, tt.minResolutionOk)
+			}
+			if v := DisableSamplingProc(chart); v != tt.disableSampling {
+				t.Errorf("DisableSamplingProc() = %v, want %v", v, tt.disableSampling)
+			}
+			if v := TimezoneProc(chart); v != tt.timezone {
+				t.Errorf("TimezoneProc() = %q, want %q", v, tt.timezone)
+			}
+		})
+	}
+}
+
+func TestSortByProc(t *testing.T) {
+	tests := []struct {
+		name         string
+		chart        string
+		want         string
+		approximated int
+	}{
+		{"not sorted", `{"options": {"type": "List"}}`, "", 0},
+		{"sort by with prefix", `{"options": {"sortBy": "-value"}}`, "-value", 0},
+		{"sort by with direction", `{"options": {"sortBy": "value", "sortDirection": "Descending"}}`, "-value", 0},
+		{"sort by without direction", `{"options": {"sortBy": "value"}}`, "+value", 1},
+		{"sort property descending", `{"options": {"sortProperty": "value", "sortDirection": "Descending"}}`, "-value", 0},
+		{"sort property ascending", `{"options": {"sortProperty": "sf_metric", "sortDirection": "Ascending"}}`, "+sf_metric", 0},
+		{"sort property without direction", `{"options": {"sortProperty": "value"}}`, "+value", 1},
+		{"unknown direction", `{"options": {"sortProperty": "value", "sortDirection": "Random"}}`, "+value", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diagnostics Diagnostics
+			opts := &Options{Diagnostics: &diagnostics}
+			if got := SortByProc(testChart(t, tt.chart), opts.Diagnoser(KindChart, "A")); got != tt.want {
+				t.Errorf("SortByProc() = %q, want %q", got, tt.want)
+			}
+			if got := diagnostics.Count(SeverityApproximated); got != tt.approximated {
+				t.Errorf("%d approximated diagnostics, want %d", got, tt.approximated)
+			}
+		})
+	}
+}
+
+func TestHeredocProcRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"single line", "# Title"},
+		{"single line with template", "${host} is %{down}"},
+		{"final newline", "A = data('cpu').publish()\n"},
+		{"no final newline", "A = data('cpu')\nA.publish()"},
+		{"several final newlines", "# Title\n\n"},
+		{"template sequences", "${host}\n%{ if down }"},
+		{"delimiter lines", "EOF\n  EOF1\nEOF2"},
+	}
+
+	// chomp of terraform, it removes all newlines at the end
+	chomp := function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "str", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(strings.TrimRight(args[0].AsString(), "\r\n")), nil
+		},
+	})
+	ctx := &hcl.EvalContext{Functions: map[string]function.Function{"chomp": chomp}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := hclwrite.NewEmptyFile()
+			HeredocProc(f.Body(), "text", tt.text)
+
+			file, diags := hclsyntax.ParseConfig(f.Bytes(), "test.tf", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("Can't parse %s: %v", f.Bytes(), diags)
+			}
+			attrs, diags := file.Body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatalf("Can't get attributes: %v", diags)
+			}
+			value, diags := attrs["text"].Expr.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("Can't evaluate %s: %v", f.Bytes(), diags)
+			}
+			if got := value.AsString(); got != tt.text {
+				t.Errorf("HeredocProc() = %q, want %q, HCL:\n%s", got, tt.text, f.Bytes())
+			}
+		})
+	}
+}
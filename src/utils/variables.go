@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Variable - terraform variable generated instead of value of the object
+type Variable struct {
+	Name        string
+	Description string
+	Default     string // empty default isn't written
+	Sensitive   bool
+}
+
+// Variables - terraform variables generated instead of webhook secrets and credential ids,
+// secrets are kept in clear text and credential ids as is unless enabled
+type Variables struct {
+	secrets     bool
+	credentials bool
+
+	list            []*Variable
+	used            map[string]bool   // assigned variable names
+	credentialNames map[string]string // credential id -> variable name
+}
+
+// NewVariables - create variables collector, secrets and credentials enable replacement of values
+func NewVariables(secrets bool, credentials bool) *Variables {
+	return &Variables{
+		secrets:         secrets,
+		credentials:     credentials,
+		used:            map[string]bool{},
+		credentialNames: map[string]string{},
+	}
+}
+
+// List - all generated variables in order of creation
+func (v *Variables) List() []*Variable {
+	if v == nil {
+		return nil
+	}
+	return v.list
+}
+
+// Secret - field with secret value, sensitive variable without default replaces it if secrets are enabled
+func (v *Variables) Secret(name string, description string, value string) Field {
+	if v == nil || !v.secrets || value == "" {
+		return Field{Value: value}
+	}
+	variable := &Variable{Name: v.name(name), Description: description, Sensitive: true}
+	v.list = append(v.list, variable)
+	return Field{Variable: variable.Name}
+}
+
+// Credential - field with credential id of the integration, variable with the id as default replaces it if credentials are enabled,
+// all references to the same credential share one variable
+func (v *Variables) Credential(integration string, id string) Field {
+	if v == nil || !v.credentials || id == "" {
+		return Field{Value: id}
+	}
+	if name, ok := v.credentialNames[id]; ok {
+		return Field{Variable: name}
+	}
+	variable := &Variable{
+		Name:        v.name("credential_" + integration + "_" + id),
+		Description: fmt.Sprintf("%s integration credential id", integration),
+		Default:     id,
+	}
+	v.list = append(v.list, variable)
+	v.credentialNames[id] = variable.Name
+	return Field{Variable: variable.Name}
+}
+
+// name - unique variable name, collisions get numeric suffix
+func (v *Variables) name(name string) string {
+	name = Slugify(name)
+	unique := name
+	for i := 2; v.used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	v.used[unique] = true
+	return unique
+}
+
+// Field - part of generated string, literal value or reference to variable
+type Field struct {
+	Value    string
+	Variable string
+}
+
+// Template - string joined from fields
+type Template []Field
+
+// Literals - template of literal fields
+func Literals(values ...string) Template {
+	t := make(Template, len(values))
+	for i, value := range values {
+		t[i] = Field{Value: value}
+	}
+	return t
+}
+
+// hasVariables - true if any field references variable
+func (t Template) hasVariables() bool {
+	for _, f := range t {
+		if f.Variable != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// String - template joined with separator, variables are written as references
+func (t Template) String(separator string) string {
+	s := ""
+	for i, f := range t {
+		if i > 0 {
+			s += separator
+		}
+		if f.Variable != "" {
+			s += "${var." + f.Variable + "}"
+		} else {
+			s += f.Value
+		}
+	}
+	return s
+}
+
+// tokens - tokens of quoted template joined with separator, literals are escaped
+func (t Template) tokens(separator string) hclwrite.Tokens {
+	tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)}}
+	literal := ""
+	flush := func() {
+		// quoted literal token of the escaped string value, without quotes
+		quoted := hclwrite.TokensForValue(cty.StringVal(literal))
+		tokens = append(tokens, quoted[1:len(quoted)-1]...)
+		literal = ""
+	}
+	for i, f := range t {
+		if i > 0 {
+			literal += separator
+		}
+		if f.Variable == "" {
+			literal += f.Value
+			continue
+		}
+		flush()
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")},
+			&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("var")},
+			&hclwrite.Token{Type: hclsyntax.TokenDot, Bytes: []byte(".")},
+			&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(f.Variable)},
+			&hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")},
+		)
+	}
+	flush()
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)})
+}
+
+// TemplateListProc - set attribute to list of templates joined with separator,
+// list without variables is set as plain strings
+func TemplateListProc(body *hclwrite.Body, name string, templates []Template, separator string) {
+	withVariables := false
+	values := make([]cty.Value, len(templates))
+	for i, t := range templates {
+		withVariables = withVariables || t.hasVariables()
+		values[i] = cty.StringVal(t.String(separator))
+	}
+	if !withVariables {
+		if len(values) == 0 {
+			body.SetAttributeValue(name, cty.ListValEmpty(cty.String))
+		} else {
+			body.SetAttributeValue(name, cty.TupleVal(values))
+		}
+		return
+	}
+
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte(name)},
+		{Type: hclsyntax.TokenEqual, Bytes: []byte("=")},
+		{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")},
+	}
+	for i, t := range templates {
+		if i > 0 {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		tokens = append(tokens, t.tokens(separator)...)
+	}
+	tokens = append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")},
+		&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	)
+	body.AppendUnstructuredTokens(tokens)
+}